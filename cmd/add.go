@@ -11,9 +11,27 @@ import (
 const (
 	colorList = "berry_red, red, orange, yellow, olive_green, lime_green, green, mint_green, teal, sky_blue, light_blue, blue, grape, violet, lavender, magenta, salmon, charcoal, grey, taupe"
 
-	addLong = `Add a new resource to Todoist (currently supports: project, task).
+	addLong = `Add a new resource to Todoist (currently supports: project, task, reminder).
 `
 
+	addReminderLong = `Add a reminder to a task.
+
+Use <code>#[PARENT/SUBPARENT.../]PROJECT</code> to specify the project name of the task, or the
+<code>--project</code> flag, as for <code>add task</code>. <code>TASK</code> is the beginning of
+the task content (case-insensitive), and must match a single incomplete task.
+
+Use <code>--at</code> for a reminder at a given time, as <code>YYYY-MM-DD HH:MM</code> or a
+string like 'tomorrow 9am', or <code>--before</code> for a reminder the given minutes (or a
+duration like 1h30m) before the task is due, which needs a due time.
+`
+
+	addReminderExample = `# Remind of task Submit report of project Work on January 15 at 9:00:
+todoister add reminder '#Work' 'Submit report' --at '2026-01-15 09:00'
+
+# Remind of it one hour before it is due:
+todoister add reminder -p Work 'Submit report' --before 60
+todoister add reminder -p Work 'Submit' --before 1h`
+
 	addProjectLong = `Add a new project to Todoist.
 
 <code>NAME</code> is the name of the project to create.
@@ -43,6 +61,9 @@ Use <code>#[PARENT/SUBPARENT.../]PROJECT</code> to specify the project name with
 
 Alternatively, you can use the <code>--project</code> flag to specify the project name
 and omit the '<code>#</code>' prefix and the quotes.
+
+Use <code>--assignee</code> to assign the task to a collaborator of a shared project, by
+email or full name (case-insensitive).
 `
 
 	addTaskExample = `# Add task to root-level project Work:
@@ -64,13 +85,18 @@ todoister add task -p Work -d 'Jan. 16' 'Submit another report'
 todoister add task -p Work --date='2026-01-16' 'Submit yet another report'
 todoister add task -p Work -d 'next tuesday 14:00' 'Team meeting'
 todoister add task -p Personal -d 'tomorrow' 'Call dentist'
-todoister add task -p Personal --date='every friday' 'Weekly review'`
+todoister add task -p Personal --date='every friday' 'Weekly review'
+
+# Add task assigned to a collaborator of shared project Team:
+todoister add task -p Team -a alice@example.com 'Review budget'
+todoister add task -p Team --assignee='Alice Smith' 'Review budget'`
 )
 
 var (
 	projectColor string
 	projectFlag  string
 	dateFlag     string
+	assigneeFlag string
 )
 
 var addProjectCmd = &cobra.Command{
@@ -98,25 +124,32 @@ var addProjectCmd = &cobra.Command{
 			parentPath = strings.Join(parts[:len(parts)-1], "/")
 
 			// Fetch Todoist data and find the parent ID
-			todoistData := util.GetTodoistData(ConfigValue.Token)
+			todoistData := util.GetTodoistData(cmd.Context(), TodoistClient, util.ResourceProjects)
 			parentID = util.GetProjectIDByPathFromProjects(parentPath, todoistData.Projects)
 
 			if parentID == "" {
-				util.Die(fmt.Sprintf("Parent project '%s' not found", parentPath), nil)
+				util.Die(fmt.Sprintf("Parent project '%s'", parentPath), util.ErrNotFound)
 			}
 		}
 
-		// Create the project
-		project, err := util.CreateProject(ConfigValue.Token, projectName, parentID, projectColor)
+		// Create the project, or queue it if offline
+		batch := util.NewCommandBatch()
+		batch.ProjectAdd(util.ProjectAddArgs(projectName, parentID, projectColor))
+		summary := fmt.Sprintf("add project '%s'", path)
+		queued, err := TodoistClient.CommitOrQueue(cmd.Context(), batch, summary)
 		if err != nil {
 			util.Die("Failed to create project", err)
 		}
+		if queued {
+			printQueued(summary)
+			return
+		}
 
 		// Print success message
 		if parentPath != "" {
-			fmt.Printf("Created project '%s' in '%s'\n", project.Name, parentPath)
+			fmt.Printf("Created project '%s' in '%s'\n", projectName, parentPath)
 		} else {
-			fmt.Printf("Created project '%s'\n", project.Name)
+			fmt.Printf("Created project '%s'\n", projectName)
 		}
 	},
 }
@@ -163,8 +196,12 @@ var addTaskCmd = &cobra.Command{
 		var parentPath string
 		var projectID string
 
-		// Fetch Todoist data and find the project ID
-		todoistData := util.GetTodoistData(ConfigValue.Token)
+		// Fetch Todoist data and find the project ID, and the collaborators to assign the task to
+		resourceTypes := []string{util.ResourceProjects}
+		if assigneeFlag != "" {
+			resourceTypes = append(resourceTypes, util.ResourceCollaborators, util.ResourceCollaboratorStates)
+		}
+		todoistData := util.GetTodoistData(cmd.Context(), TodoistClient, resourceTypes...)
 		projects := todoistData.Projects
 
 		// If there are parent parts, we need to find the project by path
@@ -173,7 +210,7 @@ var addTaskCmd = &cobra.Command{
 			projectID = util.GetProjectIDByPathFromProjects(projectPath, projects)
 
 			if projectID == "" {
-				util.Die(fmt.Sprintf("Project '%s' not found", projectPath), nil)
+				util.Die(fmt.Sprintf("Project '%s'", projectPath), util.ErrNotFound)
 			}
 		} else {
 			// Single project name - find it by name
@@ -185,7 +222,7 @@ var addTaskCmd = &cobra.Command{
 			}
 
 			if projectID == "" {
-				util.Die(fmt.Sprintf("Root-level project '%s' not found", projectName), nil)
+				util.Die(fmt.Sprintf("Root-level project '%s'", projectName), util.ErrNotFound)
 			}
 		}
 
@@ -199,21 +236,108 @@ var addTaskCmd = &cobra.Command{
 			}
 		}
 
-		// Create the task with date parameters
-		task, err := util.CreateTask(ConfigValue.Token, taskTitle, projectID, dateParams)
+		// Find the collaborator to assign the task to, if any
+		var assigneeID string
+		if assigneeFlag != "" {
+			collaborators := util.FindCollaborators(projectID, assigneeFlag, todoistData)
+			switch {
+			case len(collaborators) == 0:
+				util.Die(fmt.Sprintf("Collaborator '%s' of project '%s'", assigneeFlag, projectPath), util.ErrNotFound)
+			case len(collaborators) > 1:
+				util.Die(fmt.Sprintf("Collaborator '%s'", assigneeFlag),
+					fmt.Errorf("%w, %d collaborators have that name, use their email instead", util.ErrAmbiguous, len(collaborators)))
+			}
+			assigneeID = collaborators[0].ID
+		}
+
+		// Create the task with date parameters, or queue it if offline
+		batch := util.NewCommandBatch()
+		batch.ItemAdd(util.TaskAddArgs(taskTitle, projectID, dateParams, assigneeID))
+		summary := fmt.Sprintf("add task '%s' in '%s'", taskTitle, projectPath)
+		queued, err := TodoistClient.CommitOrQueue(cmd.Context(), batch, summary)
 		if err != nil {
 			util.Die("Failed to create task", err)
 		}
+		if queued {
+			printQueued(summary)
+			return
+		}
 
 		// Print success message
 		if parentPath != "" {
-			fmt.Printf("Created task '%s' in '%s/%s'\n", task.Content, parentPath, projectName)
+			fmt.Printf("Created task '%s' in '%s/%s'\n", taskTitle, parentPath, projectName)
 		} else {
-			fmt.Printf("Created task '%s' in '%s'\n", task.Content, projectName)
+			fmt.Printf("Created task '%s' in '%s'\n", taskTitle, projectName)
 		}
 	},
 }
 
+var addReminderCmd = &cobra.Command{
+	Use:     "reminder [flags] [#][PARENT/.../PROJECT] TASK",
+	Short:   "Add a reminder to a task",
+	Long:    addReminderLong,
+	Example: addReminderExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if projectFlag != "" && len(args) != 1 {
+			return fmt.Errorf("when using --project flag, only TASK is required")
+		} else if projectFlag == "" && len(args) != 2 {
+			return fmt.Errorf("expected PROJECT_PATH and TASK, or use --project flag")
+		}
+		if (reminderAt == "") == (reminderBefore == "") {
+			return fmt.Errorf("expected either --at or --before")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		projectPath, taskPrefix := projectFlag, args[0]
+		if projectFlag == "" {
+			projectPath, taskPrefix = strings.TrimPrefix(args[0], "#"), args[1]
+		}
+
+		todoistData := util.GetTodoistData(cmd.Context(), TodoistClient, util.ResourceProjects, util.ResourceItems)
+		task, err := util.FindTask(projectPath, taskPrefix, todoistData)
+		if err != nil {
+			util.Die("Failed to add reminder", err)
+		}
+
+		var at *util.DateParams
+		minutes := 0
+		if reminderAt != "" {
+			if at, err = util.ParseDateInput(reminderAt); err != nil || at.DueDate != "" {
+				util.Die(fmt.Sprintf("Invalid --at, expected a date and time: %s", reminderAt), err)
+			}
+		} else {
+			minutes = parseMinutes(reminderBefore)
+			if _, ok := util.DueTime(task.Due); !ok {
+				util.Die(fmt.Sprintf("Task '%s' has no due time to remind of before", task.Content), nil)
+			}
+		}
+
+		// Add the reminder, or queue it if offline
+		batch := util.NewCommandBatch()
+		batch.ReminderAdd(util.ReminderAddArgs(task.ID, at, minutes))
+		summary := fmt.Sprintf("add reminder of task '%s'", task.Content)
+		queued, err := TodoistClient.CommitOrQueue(cmd.Context(), batch, summary)
+		if err != nil {
+			util.Die("Failed to add reminder", err)
+		}
+		if queued {
+			printQueued(summary)
+			return
+		}
+
+		reminder := util.TodoistReminder{}
+		if at != nil {
+			reminder.Type = util.ReminderAbsolute
+			reminder.Due = &util.Due{Date: at.DueDateTime, String: at.DueString}
+		} else {
+			reminder.Type = util.ReminderRelative
+			reminder.MinuteOffset = minutes
+		}
+		fmt.Printf("Added reminder of task '%s' %s\n", task.Content, describeReminder(reminder, task))
+	},
+}
+
 var addCmd = &cobra.Command{
 	Use:   "add <resource> [arguments]",
 	Short: "Add a new resource",
@@ -229,10 +353,21 @@ func init() {
 		"project name or path (e.g., 'Work' or 'Work/Reports')")
 	addTaskCmd.Flags().StringVarP(&dateFlag, "date", "d", "",
 		"due date (YYYY-MM-DD, YYYY-MM-DD HH:MM, or a string like 'tomorrow',\nsee https://www.todoist.com/help/articles/introduction-to-dates-and-time\nfor help on how to write natural language dates )")
+	addTaskCmd.Flags().StringVarP(&assigneeFlag, "assignee", "a", "",
+		"email or name of the collaborator of a shared project to assign the task to")
 	addTaskCmd.SetHelpFunc(util.CustomHelpFunc)
 
+	addReminderCmd.Flags().StringVarP(&projectFlag, "project", "p", "",
+		"project name or path (e.g., 'Work' or 'Work/Reports')")
+	addReminderCmd.Flags().StringVar(&reminderAt, "at", "",
+		"remind at date and time <string> (YYYY-MM-DD HH:MM, or a string like 'tomorrow 9am')")
+	addReminderCmd.Flags().StringVar(&reminderBefore, "before", "",
+		"remind <string> minutes, or a duration like 1h30m, before the task is due")
+	addReminderCmd.SetHelpFunc(util.CustomHelpFunc)
+
 	addCmd.AddCommand(addProjectCmd)
 	addCmd.AddCommand(addTaskCmd)
+	addCmd.AddCommand(addReminderCmd)
 	addCmd.SetHelpFunc(util.CustomHelpFunc)
 
 	RootCmd.AddCommand(addCmd)
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/layfellow/todoister/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	authLong = `Manage Todoist authentication (currently supports: login, logout, status).
+`
+
+	authLoginLong = `Log in to Todoist with OAuth.
+
+Opens the Todoist authorization page in a browser and waits for Todoist to redirect
+back to a temporary listener on <code>127.0.0.1</code>. The resulting token is saved in
+<code>credentials.json</code> next to the configuration file, readable only by you, or in the
+Secret Service keyring if <code>token_store</code> is <code>secret-service</code>.
+
+You must register an application in the Todoist App Management Console and set
+<code>client_id</code> and <code>client_secret</code> in the <code>[oauth]</code> section of the configuration file.
+Its OAuth redirect URL must be <code>http://127.0.0.1:PORT/callback</code>, where <code>PORT</code> is the
+<code>redirect_port</code> configured in the same section.
+
+A token set with <code>--token</code>, <code>TODOIST_TOKEN</code>, or the <code>token</code> or <code>token_command</code>
+configuration keys takes precedence over the saved one.
+`
+
+	authLoginExample = `# Log in, opening the browser:
+todoister auth login
+
+# Log in on a remote machine, printing the URL instead:
+todoister auth login --no-browser`
+
+	authLogoutLong = `Log out of Todoist.
+
+Revokes the token saved by <code>auth login</code> and deletes it.
+`
+
+	authStatusLong = `Show the active Todoist account.
+
+Prints where the token in use comes from, the account it belongs to and its scopes.
+Exits with code <code>3</code> if there is no valid token.
+`
+)
+
+var noBrowser bool
+
+// credentialStore returns the store configured with token_store.
+func credentialStore() util.CredentialStore {
+	store, err := util.NewCredentialStore(ConfigValue.TokenStore)
+	if err != nil {
+		util.Die("Invalid configuration", err)
+	}
+	return store
+}
+
+// openBrowser opens a URL in the user's browser, on a best-effort basis.
+func openBrowser(url string) {
+	var command string
+	switch runtime.GOOS {
+	case "darwin":
+		command = "open"
+	default:
+		command = "xdg-open"
+	}
+	if err := exec.Command(command, url).Start(); err != nil {
+		util.Warn("Failed to open browser", err)
+	}
+}
+
+// tokenSource returns where the token comes from, without reading it, or an empty string
+// if it would come from the saved credentials, if any.
+func tokenSource() string {
+	if ConfigValue.Token == "" && ConfigValue.TokenCommand != "" {
+		return util.TokenFromCommand
+	}
+	return ConfigValue.TokenSource
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:     "login [flags]",
+	Short:   "Log in to Todoist with OAuth",
+	Long:    authLoginLong,
+	Example: authLoginExample,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		credentials, err := util.Login(cmd.Context(), TodoistClient, ConfigValue.OAuth, func(url string) error {
+			fmt.Printf("Open this URL in your browser to authorize %s:\n\n  %s\n\n", util.Prog, url)
+			if !noBrowser {
+				openBrowser(url)
+			}
+			fmt.Println("Waiting for authorization...")
+			return nil
+		})
+		if err != nil {
+			util.Die("Failed to log in", err)
+		}
+
+		store := credentialStore()
+		if err := store.Save(credentials); err != nil {
+			util.Die("Failed to save credentials", err)
+		}
+
+		TodoistClient.Token = credentials.AccessToken
+		util.SetCacheAccount(credentials.AccessToken)
+		user, err := TodoistClient.GetUser(cmd.Context())
+		if err != nil {
+			util.Die("Failed to get account", err)
+		}
+
+		fmt.Printf("Logged in as %s <%s>\n", user.FullName, user.Email)
+		fmt.Printf("Token saved to %s\n", store.Location())
+		if source := tokenSource(); source == "" || source == util.TokenFromCredentials {
+			// The saved token is the one used from now on
+			if err := util.RememberTokenAccount(credentials.AccessToken); err != nil {
+				util.Warn("Failed to record the account of the token", err)
+			}
+		} else {
+			util.Warn(fmt.Sprintf("The token from the %s takes precedence over the saved one", source), nil)
+		}
+	},
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Log out of Todoist",
+	Long:  authLogoutLong,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		store := credentialStore()
+		credentials, err := store.Load()
+		if err != nil {
+			util.Die("Failed to load credentials", err)
+		}
+		if credentials == nil {
+			fmt.Println("Not logged in")
+			return
+		}
+
+		if ConfigValue.ClientID == "" || ConfigValue.ClientSecret == "" {
+			util.Warn("Missing oauth.client_id or oauth.client_secret, the token was not revoked", nil)
+		} else {
+			TodoistClient.Token = credentials.AccessToken
+			if err := TodoistClient.RevokeToken(cmd.Context(), ConfigValue.OAuth); err != nil {
+				util.Warn("The token was not revoked", err)
+			}
+		}
+
+		if _, err := store.Remove(); err != nil {
+			util.Die("Failed to delete credentials", err)
+		}
+		if source := tokenSource(); source == "" || source == util.TokenFromCredentials {
+			if err := util.RememberTokenAccount(""); err != nil {
+				util.Warn("Failed to record the account of the token", err)
+			}
+		}
+		fmt.Println("Logged out")
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the active Todoist account",
+	Long:  authStatusLong,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if TodoistClient.AuthToken() == "" {
+			util.Die("Not logged in", util.ErrAuth)
+		}
+
+		user, err := TodoistClient.GetUser(cmd.Context())
+		if err != nil {
+			util.Die("Failed to verify token", err)
+		}
+
+		scope := "unknown, the token was not obtained with auth login"
+		if ConfigValue.TokenSource == util.TokenFromCredentials {
+			if credentials, err := credentialStore().Load(); err == nil && credentials != nil {
+				scope = credentials.Scope
+			}
+		}
+
+		fmt.Printf("Account: %s <%s>\n", user.FullName, user.Email)
+		fmt.Printf("Token:   %s (from %s)\n", util.MaskToken(ConfigValue.Token), ConfigValue.TokenSource)
+		fmt.Printf("Scopes:  %s\n", scope)
+	},
+}
+
+var authCmd = &cobra.Command{
+	Use:   "auth <command>",
+	Short: "Manage authentication",
+	Long:  authLong,
+}
+
+func init() {
+	authLoginCmd.Flags().BoolVar(&noBrowser, "no-browser", false,
+		"print the authorization URL without opening a browser")
+	authLoginCmd.SetHelpFunc(util.CustomHelpFunc)
+	authLogoutCmd.SetHelpFunc(util.CustomHelpFunc)
+	authStatusCmd.SetHelpFunc(util.CustomHelpFunc)
+
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authLogoutCmd)
+	authCmd.AddCommand(authStatusCmd)
+	authCmd.SetHelpFunc(util.CustomHelpFunc)
+
+	RootCmd.AddCommand(authCmd)
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/layfellow/todoister/util"
+)
+
+func TestAuthLogin(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	const accessToken = "0123456789abcdef0123456789abcdef01234567"
+	var challenge string
+	revoked := false
+
+	// Stand-in for the Todoist OAuth endpoints and API
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("client_id") != "client" || query.Get("code_challenge_method") != "S256" {
+			t.Errorf("Unexpected authorization request: %s", r.URL.RawQuery)
+		}
+		challenge = query.Get("code_challenge")
+		redirect := query.Get("redirect_uri") + "?" + url.Values{
+			"code": {"abcdef"}, "state": {query.Get("state")},
+		}.Encode()
+		http.Redirect(w, r, redirect, http.StatusFound)
+	})
+	mux.HandleFunc("/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			t.Error("PKCE code verifier doesn't match the challenge")
+		}
+		if r.FormValue("code") != "abcdef" || r.FormValue("client_secret") != "secret" {
+			t.Errorf("Unexpected token exchange: %s", r.Form.Encode())
+		}
+		_, _ = io.WriteString(w, `{"access_token":"`+accessToken+`","token_type":"Bearer"}`)
+	})
+	mux.HandleFunc("/api/v1/sync", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+accessToken {
+			http.Error(w, `{"error":"Unauthorized","http_code":401}`, http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, `{"sync_token":"abc","user":{"id":"2671355","email":"me@example.com","full_name":"Example User"}}`)
+	})
+	mux.HandleFunc("/api/v1/revoke", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		user, pass, _ := r.BasicAuth()
+		revoked = user == "client" && pass == "secret" && body["token"] == accessToken
+		_, _ = io.WriteString(w, "null")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := util.ConfigType{}
+	config.URL = server.URL + "/api/v1"
+	config.OAuth = util.OAuth{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		AuthorizeURL: server.URL + "/oauth/authorize",
+		TokenURL:     server.URL + "/oauth/access_token",
+		Scope:        util.DefaultScope,
+	}
+	client := util.NewClient(&config, "DEV")
+	ctx := context.Background()
+
+	// The "browser" follows the redirect back to the loopback listener
+	credentials, err := util.Login(ctx, client, config.OAuth, func(authURL string) error {
+		go func() {
+			if resp, err := http.Get(authURL); err == nil {
+				_ = resp.Body.Close()
+			}
+		}()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if credentials.AccessToken != accessToken || credentials.Scope != util.DefaultScope {
+		t.Errorf("Unexpected credentials: %+v", credentials)
+	}
+
+	store, err := util.NewCredentialStore(util.StoreFile)
+	if err != nil {
+		t.Fatalf("NewCredentialStore failed: %v", err)
+	}
+	if err := store.Save(credentials); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	saved, err := store.Load()
+	if err != nil || saved == nil || saved.AccessToken != accessToken {
+		t.Fatalf("Expected saved credentials, got %+v, %v", saved, err)
+	}
+
+	client.Token = saved.AccessToken
+	user, err := client.GetUser(ctx)
+	if err != nil {
+		t.Fatalf("GetUser failed: %v", err)
+	}
+	if user.Email != "me@example.com" {
+		t.Errorf("Expected user me@example.com, got %s", user.Email)
+	}
+
+	if err := client.RevokeToken(ctx, config.OAuth); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+	if !revoked {
+		t.Error("Expected token to be revoked with client credentials")
+	}
+	if removed, err := store.Remove(); !removed || err != nil {
+		t.Errorf("Expected credentials to be removed, got %v, %v", removed, err)
+	}
+}
+
+func TestAuthLoginDenied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		redirect := query.Get("redirect_uri") + "?" + url.Values{
+			"error": {"access_denied"}, "state": {query.Get("state")},
+		}.Encode()
+		http.Redirect(w, r, redirect, http.StatusFound)
+	}))
+	defer server.Close()
+
+	oauth := util.OAuth{ClientID: "client", ClientSecret: "secret", AuthorizeURL: server.URL, Scope: util.DefaultScope}
+	client := util.NewClient(&util.ConfigType{}, "DEV")
+	_, err := util.Login(context.Background(), client, oauth, func(authURL string) error {
+		go func() {
+			if resp, err := http.Get(authURL); err == nil {
+				_ = resp.Body.Close()
+			}
+		}()
+		return nil
+	})
+	if util.ExitCode(err) != util.ExitAuth {
+		t.Errorf("Expected exit code %d for denied authorization, got %d (%v)", util.ExitAuth, util.ExitCode(err), err)
+	}
+}
+
+func TestTokenCommand(t *testing.T) {
+	token, err := util.RunTokenCommand(`printf 's3cr3t \nuser: me@example.com\n'`)
+	if err != nil || token != "s3cr3t" {
+		t.Errorf("Expected token 's3cr3t', got '%s' (%v)", token, err)
+	}
+	if _, err := util.RunTokenCommand("exit 1"); err == nil {
+		t.Error("Expected an error from a failing token_command")
+	}
+	if _, err := util.RunTokenCommand("true"); err == nil {
+		t.Error("Expected an error from a token_command that prints nothing")
+	}
+}
+
+func TestTokenCommandWhenNeeded(t *testing.T) {
+	t.Cleanup(func() {
+		resetGlobalFlags()
+		ConfigValue.Token = ""
+		ConfigValue.TokenCommand = ""
+	})
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	runs := filepath.Join(t.TempDir(), "runs")
+	t.Setenv("TODOIST_TOKEN_COMMAND", "echo >> "+runs+"; echo s3cr3t")
+	ConfigValue.TokenCommand = ""
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			http.Error(w, `{"error":"Unauthorized","http_code":401}`, http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, standInSyncResponse)
+	}))
+	defer server.Close()
+	run := func(args ...string) string {
+		resetGlobalFlags()
+		ConfigValue.URL = server.URL + "/api/v1"
+		ConfigValue.Token = ""
+		return runCommand(t, args...)
+	}
+
+	// token_command runs for the sync, but not for the commands that only read the cache,
+	// which find the account of the token it printed last
+	run("tasks", "Work")
+	status := run("cache", "status")
+	if output := run("--offline", "tasks", "Work"); !strings.Contains(output, "Write report") {
+		t.Errorf("Unexpected offline tasks:\n%s", output)
+	}
+	if data, _ := os.ReadFile(runs); string(data) != "\n" {
+		t.Errorf("Expected token_command to run once, ran %d times", strings.Count(string(data), "\n"))
+	}
+	if !strings.Contains(status, "Account:     "+util.AccountKey("s3cr3t")) {
+		t.Errorf("Expected the cache of the token's account:\n%s", status)
+	}
+}
+
+func TestCheckConfigPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("token = \"abc\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := util.CheckConfigPermissions(path); err != nil {
+		t.Errorf("Expected a private file to pass, got %v", err)
+	}
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := util.CheckConfigPermissions(path); err == nil {
+		t.Error("Expected a world-readable file to fail")
+	}
+}
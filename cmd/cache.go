@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/layfellow/todoister/util"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+const (
+	cacheLong = `Inspect and manage the local cache (currently supports: status, clear, refresh, dump, verify).
+
+Todoister keeps a copy of your Todoist data in <code>todoist.pb</code>, under
+<code>$XDG_CACHE_HOME/todoister/accounts</code> or <code>~/.cache/todoister/accounts</code>, and only
+fetches the changes since the last sync. Each account has its own cache, in a directory
+named after a hash of its token.
+`
+
+	cacheExample = `# Show the state of the cache:
+todoister cache status
+
+# Discard the cache and fetch everything again:
+todoister cache refresh
+
+# Save the cache as JSON:
+todoister cache dump --json > todoist.json`
+
+	cacheStatusLong = `Show the state of the local cache.
+
+Prints the cache path and account, its size, whether it is encrypted, when it was last
+synced, its sync tokens and schema version, and how many resources of each type it holds.
+`
+
+	cacheClearLong = `Delete the local cache.
+
+The next command fetches all your data from Todoist again.
+Changes queued for the next sync are kept.
+`
+
+	cacheRefreshLong = `Fetch all your data from Todoist again, replacing the local cache.
+
+Unlike <code>cache clear</code>, the old cache is kept if the sync fails.
+`
+
+	cacheDumpLong = `Print the contents of the local cache.
+
+Prints the cache in Protobuf text format, or in JSON with <code>--json</code>.
+`
+
+	cacheVerifyLong = `Check the local cache for errors.
+
+Decodes the cache and checks that every task, section, reminder and comment refers to a cached
+project, section or task. Exits with code <code>1</code> if the cache is unreadable or has errors.
+`
+)
+
+var dumpJSON bool
+
+// formatSize returns a size in bytes in human-readable units.
+func formatSize(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size) / 1024
+	for _, unit := range []string{"KB", "MB"} {
+		if value < 1024 {
+			return fmt.Sprintf("%.1f %s", value, unit)
+		}
+		value /= 1024
+	}
+	return fmt.Sprintf("%.1f GB", value)
+}
+
+// readCache reads the cache, exiting with an error if it is missing or unreadable.
+func readCache() *util.CachedTodoistData {
+	cached, err := util.ReadCache()
+	if os.IsNotExist(err) {
+		util.Die("No cache, run a command online first", err)
+	} else if err != nil {
+		util.Die("Failed to read cache", err)
+	}
+	return cached
+}
+
+var cacheStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state of the cache",
+	Long:  cacheStatusLong,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cachePath, err := util.GetCachePath()
+		if err != nil {
+			util.Die("Failed to locate cache", err)
+		}
+		info, err := os.Stat(cachePath)
+		if os.IsNotExist(err) {
+			fmt.Println("No cache")
+			return
+		} else if err != nil {
+			util.Die("Failed to read cache", err)
+		}
+		cached := readCache()
+
+		fmt.Printf("Path:        %s\n", cachePath)
+		if user := util.CacheAccountUser(); user != "" {
+			fmt.Printf("Account:     %s (user %s)\n", util.CacheAccount(), user)
+		} else {
+			fmt.Printf("Account:     %s\n", util.CacheAccount())
+		}
+		fmt.Printf("Size:        %s\n", formatSize(info.Size()))
+		if data, err := os.ReadFile(cachePath); err == nil && util.IsEncrypted(data) {
+			fmt.Println("Encrypted:   yes")
+		} else {
+			fmt.Println("Encrypted:   no")
+		}
+		if cached.GetCachedAt() > 0 {
+			syncedAt := time.Unix(cached.GetCachedAt(), 0)
+			fmt.Printf("Synced:      %s (%s ago)\n", syncedAt.Format("Jan 2, 2006, 3:04 PM"),
+				time.Since(syncedAt).Round(time.Second))
+		}
+
+		if schema := cached.GetSchema(); schema == util.CacheSchema {
+			fmt.Printf("Schema:      %d\n", schema)
+		} else if err := util.CheckCacheSchema(cached); err != nil {
+			fmt.Printf("Schema:      %d (current is %d, the next sync rebuilds the cache: %v)\n",
+				schema, util.CacheSchema, err)
+		} else {
+			fmt.Printf("Schema:      %d (current is %d, upgraded on the next sync)\n", schema, util.CacheSchema)
+		}
+
+		// Resources synced together share a token, so print each token once
+		tokens := make(map[string][]string)
+		var order []string
+		for _, r := range util.AllResources {
+			if token, ok := cached.GetSyncTokens()[r]; ok {
+				if tokens[token] == nil {
+					order = append(order, token)
+				}
+				tokens[token] = append(tokens[token], r)
+			}
+		}
+		for _, token := range order {
+			if len(order) == 1 {
+				fmt.Printf("Sync token:  %s\n", token)
+			} else {
+				fmt.Printf("Sync token:  %s (%s)\n", token, strings.Join(tokens[token], ", "))
+			}
+		}
+		if len(order) == 0 && cached.GetSyncToken() != "" {
+			fmt.Printf("Sync token:  %s\n", cached.GetSyncToken())
+		}
+
+		fmt.Printf("Projects:    %d\n", len(cached.GetProjects()))
+		fmt.Printf("Sections:    %d\n", len(cached.GetSections()))
+		fmt.Printf("Tasks:       %d\n", len(cached.GetItems()))
+		fmt.Printf("Labels:      %d\n", len(cached.GetLabels()))
+		fmt.Printf("Comments:    %d\n", len(cached.GetComments()))
+		fmt.Printf("Reminders:   %d\n", len(cached.GetReminders()))
+		fmt.Printf("Shared with: %d collaborators\n", len(cached.GetCollaborators()))
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the cache",
+	Long:  cacheClearLong,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		lock, err := util.LockCache(cmd.Context(), util.DefaultLockTimeout)
+		if err != nil {
+			util.Die("Failed to lock cache", err)
+		}
+		defer lock.Unlock()
+
+		removed, err := util.ClearCache()
+		if err != nil {
+			util.Die("Failed to clear cache", err)
+		}
+		if removed {
+			fmt.Println("Cache cleared")
+		} else {
+			fmt.Println("No cache")
+		}
+	},
+}
+
+var cacheRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Fetch everything from Todoist again",
+	Long:  cacheRefreshLong,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if ConfigValue.Offline {
+			util.Die("Cannot refresh the cache offline", util.ErrNetwork)
+		}
+		TodoistClient.FullSync = true
+		data := util.GetTodoistData(cmd.Context(), TodoistClient)
+		fmt.Printf("Cache refreshed: %d projects, %d tasks\n", len(data.Projects), len(data.Items))
+	},
+}
+
+var cacheDumpCmd = &cobra.Command{
+	Use:   "dump [flags]",
+	Short: "Print the contents of the cache",
+	Long:  cacheDumpLong,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cached := readCache()
+		var out []byte
+		var err error
+		if dumpJSON {
+			out, err = protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(cached)
+		} else {
+			out, err = prototext.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(cached)
+		}
+		if err != nil {
+			util.Die("Failed to encode cache", err)
+		}
+		fmt.Println(string(out))
+	},
+}
+
+var cacheVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the cache for errors",
+	Long:  cacheVerifyLong,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cached := readCache()
+		problems := util.VerifyCache(cached)
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+		if len(problems) > 0 {
+			util.Die(fmt.Sprintf("Cache has %d errors, run 'todoister cache refresh' to rebuild it", len(problems)), nil)
+		}
+		fmt.Printf("Cache OK: %d projects, %d sections, %d tasks, %d comments\n",
+			len(cached.GetProjects()), len(cached.GetSections()), len(cached.GetItems()), len(cached.GetComments()))
+	},
+}
+
+var cacheCmd = &cobra.Command{
+	Use:     "cache <command>",
+	Short:   "Inspect and manage the local cache",
+	Long:    cacheLong,
+	Example: cacheExample,
+}
+
+func init() {
+	cacheDumpCmd.Flags().BoolVar(&dumpJSON, "json", false, "print the cache in JSON format")
+
+	cacheStatusCmd.SetHelpFunc(util.CustomHelpFunc)
+	cacheClearCmd.SetHelpFunc(util.CustomHelpFunc)
+	cacheRefreshCmd.SetHelpFunc(util.CustomHelpFunc)
+	cacheDumpCmd.SetHelpFunc(util.CustomHelpFunc)
+	cacheVerifyCmd.SetHelpFunc(util.CustomHelpFunc)
+
+	cacheCmd.AddCommand(cacheStatusCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheRefreshCmd)
+	cacheCmd.AddCommand(cacheDumpCmd)
+	cacheCmd.AddCommand(cacheVerifyCmd)
+	cacheCmd.SetHelpFunc(util.CustomHelpFunc)
+
+	RootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/layfellow/todoister/util"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCacheCommand(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	server := newStandInServer(t)
+	defer server.Close()
+	ConfigValue.URL = server.URL + "/api/v1"
+
+	if output := runCommand(t, "-t", "test", "cache", "status"); output != "No cache\n" {
+		t.Errorf("Unexpected status without a cache: %q", output)
+	}
+	if output := runCommand(t, "-t", "test", "cache", "refresh"); output != "Cache refreshed: 1 projects, 2 tasks\n" {
+		t.Errorf("Unexpected refresh output: %q", output)
+	}
+
+	status := runCommand(t, "-t", "test", "cache", "status")
+	for _, want := range []string{"todoist.pb\n", "Projects:    1\n", "Tasks:       2\n",
+		"Sync token:  VRyFHr0Qo3Hr--pzINyT6nax4vW7X2YG5RQlw3lB-6eYOPbSZVJepa62EVhO\n"} {
+		if !strings.Contains(status, want) {
+			t.Errorf("Expected %q in status, got:\n%s", want, status)
+		}
+	}
+
+	if output := runCommand(t, "-t", "test", "cache", "verify"); output != "Cache OK: 1 projects, 0 sections, 2 tasks, 0 comments\n" {
+		t.Errorf("Unexpected verify output: %q", output)
+	}
+
+	var dump struct {
+		Items []struct {
+			Content string `json:"content"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(runCommand(t, "-t", "test", "cache", "dump", "--json")), &dump); err != nil {
+		t.Fatalf("Expected a JSON dump: %v", err)
+	}
+	if len(dump.Items) != 2 || dump.Items[0].Content != "Write report" {
+		t.Errorf("Unexpected dump: %+v", dump)
+	}
+
+	if output := runCommand(t, "-t", "test", "cache", "clear"); output != "Cache cleared\n" {
+		t.Errorf("Unexpected clear output: %q", output)
+	}
+	if output := runCommand(t, "-t", "test", "cache", "status"); output != "No cache\n" {
+		t.Errorf("Expected no cache after clearing, got %q", output)
+	}
+}
+
+func TestVerifyCache(t *testing.T) {
+	cached := &util.CachedTodoistData{
+		Projects: []*util.PbProject{{Id: "1", Name: "Work"}, {Id: "2", ParentId: "9"}},
+		Sections: []*util.PbSection{{Id: "10", ProjectId: "1"}, {Id: "11", ProjectId: "8"}},
+		Items: []*util.PbItem{
+			{Id: "20", ProjectId: "1", SectionId: "10"},
+			{Id: "21", ProjectId: "2", SectionId: "10", ParentId: "29"},
+		},
+		Comments: []*util.PbComment{{Id: "30", TaskId: "20"}, {Id: "31", TaskId: "28"}},
+	}
+
+	want := []string{
+		"project 2 has unknown parent project 9",
+		"section 11 has unknown project 8",
+		"item 21 is in section 10 of another project",
+		"item 21 has unknown parent item 29",
+		"comment 31 has unknown item 28",
+	}
+	problems := util.VerifyCache(cached)
+	if strings.Join(problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected problems:\n%s", strings.Join(problems, "\n"))
+	}
+}
+
+func TestCachePerAccount(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	// Tokens first, renewed, renewed again and third belong to user 42, second to user 43
+	fullSyncs := 0
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if commands := r.FormValue("commands"); commands != "" {
+			var received []util.SyncCommand
+			if err := json.Unmarshal([]byte(commands), &received); err != nil {
+				t.Errorf("Failed to parse commands: %v", err)
+			}
+			status := make(map[string]string)
+			for _, c := range received {
+				status[c.UUID] = "ok"
+				sent = append(sent, c.Args["content"].(string))
+			}
+			_, _ = fmt.Fprintf(w, `{"sync_token":"abc","sync_status":%s,"temp_id_mapping":{}}`, mustJSON(t, status))
+			return
+		}
+		userID := "42"
+		if r.Header.Get("Authorization") == "Bearer second" {
+			userID = "43"
+		}
+		if r.FormValue("sync_token") == "*" && r.FormValue("resource_types") != `["user"]` {
+			fullSyncs++
+		}
+		_, _ = io.WriteString(w, strings.Replace(standInSyncResponse, `"full_sync": true,`,
+			`"full_sync": true, "user": {"id": "`+userID+`", "email": "jane@example.com", "full_name": "Jane"},`, 1))
+	}))
+	defer server.Close()
+	ConfigValue.URL = server.URL + "/api/v1"
+
+	// The cache, queue and journal shared by all accounts in release 0.4 and later
+	legacyDir := filepath.Join(cacheHome, "todoister")
+	saveLegacy := func(projectID, task string) {
+		legacy, _ := proto.Marshal(&util.CachedTodoistData{
+			SyncToken: "old",
+			Projects:  []*util.PbProject{{Id: projectID, Name: "Home"}},
+		})
+		queued := fmt.Sprintf(`{"command":{"type":"item_add","uuid":"u-%s","temp_id":"t-%s",`+
+			`"args":{"content":"%s","project_id":"%s"}},"summary":"add task","queued_at":"2025-01-01T00:00:00Z"}`+"\n",
+			task, task, task, projectID)
+		if err := os.MkdirAll(legacyDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, data := range map[string][]byte{"todoist.pb": legacy, "version": []byte("schema=0.4\n"), "queue.jsonl": []byte(queued)} {
+			if err := os.WriteFile(filepath.Join(legacyDir, name), data, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	saveLegacy("2", "Fix sink")
+
+	// The legacy cache is moved, then fully synced again as it may be another account's.
+	// It has none of the account's projects, so its queue is left behind, unsent.
+	if output := runCommand(t, "-t", "first", "list"); strings.Contains(output, "Home") || !strings.Contains(output, "Work") {
+		t.Errorf("Unexpected projects from the legacy cache:\n%s", output)
+	}
+	runCommand(t, "-t", "first", "list")
+	firstDir := filepath.Join(legacyDir, "accounts", util.AccountKey("first"))
+	if _, err := os.Stat(filepath.Join(firstDir, "todoist.pb")); err != nil {
+		t.Errorf("Expected the cache in the account's directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(firstDir, "queue.jsonl")); !os.IsNotExist(err) || len(sent) != 0 {
+		t.Errorf("Expected the queue of another account left alone, sent %v", sent)
+	}
+	if _, err := os.Stat(filepath.Join(legacyDir, "queue.jsonl")); err != nil {
+		t.Errorf("Expected the queue kept in the legacy directory: %v", err)
+	}
+
+	// The sync records the user the account belongs to
+	if status := runCommand(t, "-t", "first", "cache", "status"); !strings.Contains(status, "(user 42)\n") {
+		t.Errorf("Expected the user recorded by the sync:\n%s", status)
+	}
+
+	// Another token gets a cache of its own, with a full sync
+	runCommand(t, "-t", "second", "list")
+	runCommand(t, "-t", "first", "list")
+	if fullSyncs != 2 {
+		t.Errorf("Expected a full sync per account, got %d", fullSyncs)
+	}
+
+	// A new token of the same user takes over the files of the old one, leaving its lock
+	runCommand(t, "-t", "renewed", "auth", "status")
+	if entries, _ := os.ReadDir(firstDir); len(entries) != 1 || entries[0].Name() != "todoist.lock" {
+		t.Errorf("Expected the old token's directory to be taken over, got %v", entries)
+	}
+	status := runCommand(t, "-t", "renewed", "cache", "status")
+	if !strings.Contains(status, "Account:     "+util.AccountKey("renewed")+" (user 42)\n") {
+		t.Errorf("Unexpected status after logging in again:\n%s", status)
+	}
+	runCommand(t, "-t", "renewed", "list")
+	if fullSyncs != 3 {
+		t.Errorf("Expected a full sync after the token changed, got %d syncs", fullSyncs)
+	}
+
+	// So does a new token that syncs first, sending the changes the old one queued
+	runCommand(t, "-t", "renewed", "--offline", "add", "task", "-p", "Work", "Water plants")
+	resetGlobalFlags()
+	ConfigValue.URL = server.URL + "/api/v1"
+	runCommand(t, "-t", "renewed again", "list")
+	if strings.Join(sent, ",") != "Water plants" {
+		t.Errorf("Expected the queue of the old token sent, got %v", sent)
+	}
+	if status := runCommand(t, "-t", "renewed again", "cache", "status"); !strings.Contains(status, "(user 42)\n") {
+		t.Errorf("Unexpected status after syncing with a new token:\n%s", status)
+	}
+	if fullSyncs != 4 {
+		t.Errorf("Expected a full sync after the token changed, got %d syncs", fullSyncs)
+	}
+
+	// A legacy queue kept with the account's projects is the account's, sent on the next sync
+	saveLegacy("6Jf8VQXxpwv56VQ7", "Call client")
+	runCommand(t, "-t", "third", "list")
+	runCommand(t, "-t", "third", "list")
+	if strings.Join(sent, ",") != "Water plants,Call client" {
+		t.Errorf("Expected the legacy queue sent with its account's token, got %v", sent)
+	}
+	if _, err := os.Stat(filepath.Join(legacyDir, "queue.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("Expected the queue moved out of the legacy directory")
+	}
+}
+
+func TestCacheEncryption(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	fullSyncs := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("sync_token") == "*" {
+			fullSyncs++
+		}
+		_, _ = io.WriteString(w, standInSyncResponse)
+	}))
+	defer server.Close()
+	ConfigValue.URL = server.URL + "/api/v1"
+	ConfigValue.Passphrase = "correct horse battery staple"
+
+	runCommand(t, "-t", "test", "tasks", "Work")
+	cachePath, _ := util.GetCachePath()
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !util.IsEncrypted(data) || strings.Contains(string(data), "Write report") {
+		t.Errorf("Expected an encrypted cache")
+	}
+	if status := runCommand(t, "-t", "test", "cache", "status"); !strings.Contains(status, "Encrypted:   yes\n") {
+		t.Errorf("Unexpected status:\n%s", status)
+	}
+
+	// The encrypted cache and queue are read back with the same key
+	if output := runCommand(t, "-t", "test", "--offline", "tasks", "Work"); !strings.Contains(output, "Write report") {
+		t.Errorf("Unexpected offline tasks:\n%s", output)
+	}
+	runCommand(t, "-t", "test", "--offline", "add", "task", "-p", "Work", "Call client")
+	queuePath, _ := util.GetQueuePath()
+	if queue, _ := os.ReadFile(queuePath); !strings.HasPrefix(string(queue), "enc:") || strings.Contains(string(queue), "Call client") {
+		t.Errorf("Expected an encrypted queue, got %q", queue)
+	}
+	if output := runCommand(t, "-t", "test", "queue"); !strings.Contains(output, "Call client") {
+		t.Errorf("Unexpected queue:\n%s", output)
+	}
+	runCommand(t, "-t", "test", "queue", "drop", "--all")
+
+	// A key from a command, not the one the cache was saved with, means a full sync
+	resetGlobalFlags()
+	ConfigValue.URL = server.URL + "/api/v1"
+	ConfigValue.KeyCommand = "echo 0123456789abcdef0123456789abcdef"
+	if output := runCommand(t, "-t", "test", "tasks", "Work"); !strings.Contains(output, "Write report") {
+		t.Errorf("Unexpected tasks with another key:\n%s", output)
+	}
+	if fullSyncs != 2 {
+		t.Errorf("Expected a full sync with another key, got %d full syncs", fullSyncs)
+	}
+	runCommand(t, "-t", "test", "tasks", "Work")
+	if fullSyncs != 2 {
+		t.Errorf("Expected the cache saved with the new key to be used, got %d full syncs", fullSyncs)
+	}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/layfellow/todoister/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	changesLong = `List the changes to your Todoist data found by recent syncs.
+
+Every sync records what it finds added, updated, completed or deleted in an append-only
+journal, <code>journal.jsonl</code>, next to the cache, so you can see what your teammates did
+in shared projects. Changes you make with Todoister are recorded too.
+The first sync of each resource type records nothing, as there is nothing to compare with.
+
+<code>--since</code> takes a duration, e.g., <code>30m</code>, <code>24h</code> or <code>168h</code>.
+<code>--project</code> takes a project name or path, e.g., <code>Work/Reports</code>, and includes
+its subprojects.
+`
+
+	changesExample = `# List the changes in the last 24 hours:
+todoister changes
+
+# List the changes in project Work in the last week:
+todoister changes --since 168h --project Work`
+)
+
+var changesSince time.Duration
+var changesProjectFlag string
+
+// changeSymbols mark each kind of change.
+var changeSymbols = map[string]string{
+	util.ChangeAdded:     "+",
+	util.ChangeUpdated:   "~",
+	util.ChangeCompleted: "✓",
+	util.ChangeDeleted:   "-",
+}
+
+// describeChange returns a one-line description of a change, e.g.,
+// "+ Added task 'Write report' in Work".
+func describeChange(entry util.JournalEntry) string {
+	change := entry.Change
+	if change != "" {
+		change = strings.ToUpper(change[:1]) + change[1:]
+	}
+	line := fmt.Sprintf("%s %s %s '%s'", changeSymbols[entry.Change], change, entry.Kind, entry.Name)
+	if entry.Kind == util.KindProject {
+		if i := strings.LastIndex(entry.Project, "/"); i >= 0 {
+			line += " in " + entry.Project[:i]
+		}
+	} else if entry.Project != "" {
+		line += " in " + entry.Project
+	}
+	if len(entry.Fields) > 0 {
+		line += " (" + strings.Join(entry.Fields, ", ") + ")"
+	}
+	return line
+}
+
+var changesCmd = &cobra.Command{
+	Use:     "changes [flags]",
+	Short:   "List recent changes",
+	Long:    changesLong,
+	Example: changesExample,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if changesSince <= 0 {
+			util.Die(fmt.Sprintf("Invalid --since duration: %s", changesSince), nil)
+		}
+
+		// Sync first, so the journal is up to date
+		util.GetTodoistData(cmd.Context(), TodoistClient)
+		entries, err := util.LoadJournal(time.Now().Add(-changesSince))
+		if err != nil {
+			util.Die("Failed to read the change journal", err)
+		}
+
+		prefix := strings.ToLower(strings.Trim(changesProjectFlag, "/"))
+		day, count := "", 0
+		for _, entry := range entries {
+			path := strings.ToLower(entry.Project)
+			if prefix != "" && path != prefix && !strings.HasPrefix(path, prefix+"/") {
+				continue
+			}
+			count++
+			at := entry.At.Local()
+			if d := at.Format("Mon, Jan 2, 2006"); d != day {
+				day = d
+				fmt.Printf("\n# %s\n\n", day)
+			}
+			fmt.Printf("  %8s  %s\n", at.Format("3:04 PM"), describeChange(entry))
+		}
+		if count == 0 {
+			fmt.Println("No changes")
+		}
+	},
+}
+
+func init() {
+	changesCmd.Flags().DurationVar(&changesSince, "since", 24*time.Hour,
+		"list changes in the last <duration>")
+	changesCmd.Flags().StringVarP(&changesProjectFlag, "project", "p", "",
+		"list only changes in project <string>, including its subprojects")
+	changesCmd.SetHelpFunc(util.CustomHelpFunc)
+
+	RootCmd.AddCommand(changesCmd)
+}
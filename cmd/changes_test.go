@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/layfellow/todoister/util"
+)
+
+func TestChangesJournal(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// A full sync, then one change of each kind, then nothing new
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.FormValue("sync_token") {
+		case "*":
+			_, _ = io.WriteString(w, standInSyncResponse)
+		case "t2":
+			_, _ = io.WriteString(w, `{"sync_token":"t2","full_sync":false}`)
+		default:
+			_, _ = io.WriteString(w, `{"sync_token":"t2","full_sync":false,
+				"projects":[{"id":"p2","name":"Home","parent_id":null}],
+				"items":[
+					{"id":"6X7rM8997g3RQmvh","project_id":"6Jf8VQXxpwv56VQ7","content":"Write final report","priority":1,"child_order":1,"labels":[]},
+					{"id":"6X7rfFVPjhvv84XG","project_id":"6Jf8VQXxpwv56VQ7","content":"Buy paper","is_deleted":true},
+					{"id":"i3","project_id":"p2","content":"Water plants","priority":1,"completed_at":"2025-01-02T10:00:00Z","labels":[]}]}`)
+		}
+	}))
+	defer server.Close()
+	ConfigValue.URL = server.URL + "/api/v1"
+
+	if output := runCommand(t, "-t", "test", "changes"); output != "No changes\n" {
+		t.Errorf("Unexpected output after the first sync: %q", output)
+	}
+
+	output := runCommand(t, "-t", "test", "changes")
+	for _, expected := range []string{
+		"+ Added project 'Home'\n",
+		"~ Updated task 'Write final report' in Work (content)\n",
+		"- Deleted task 'Buy paper' in Work\n",
+		"+ Added task 'Water plants' in Home\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, output)
+		}
+	}
+
+	output = runCommand(t, "-t", "test", "changes", "--project", "work")
+	if strings.Contains(output, "Home") || !strings.Contains(output, "Buy paper") {
+		t.Errorf("Unexpected output for project Work:\n%s", output)
+	}
+}
+
+func TestChangesWrittenThrough(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// The sync after adding a task returns it with all the fields Todoist fills in
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.FormValue("commands") != "":
+			var received []util.SyncCommand
+			if err := json.Unmarshal([]byte(r.FormValue("commands")), &received); err != nil {
+				t.Errorf("Failed to parse commands: %v", err)
+			}
+			_, _ = fmt.Fprintf(w, `{"sync_token":"t1","sync_status":{%q:"ok"},"temp_id_mapping":{%q:"i9"}}`,
+				received[0].UUID, received[0].TempID)
+		case r.FormValue("sync_token") == "*":
+			_, _ = io.WriteString(w, standInSyncResponse)
+		default:
+			_, _ = io.WriteString(w, `{"sync_token":"t2","full_sync":false,
+				"items":[{"id":"i9","project_id":"6Jf8VQXxpwv56VQ7","content":"Call client","priority":1,
+					"child_order":7,"labels":[],"added_at":"2025-01-02T10:00:00Z","updated_at":"2025-01-02T10:00:00Z",
+					"due":{"date":"2099-01-16","is_recurring":false,"string":"Jan 16","timezone":null}}]}`)
+		}
+	}))
+	defer server.Close()
+	run := func(args ...string) string {
+		resetGlobalFlags()
+		ConfigValue.URL = server.URL + "/api/v1"
+		return runCommand(t, append([]string{"-t", "test"}, args...)...)
+	}
+
+	run("changes")
+	run("add", "task", "-p", "Work", "Call client", "-d", "2099-01-16")
+	output := run("changes")
+	if strings.Count(output, "Call client") != 1 || !strings.Contains(output, "+ Added task 'Call client' in Work\n") {
+		t.Errorf("Expected only the task added, got:\n%s", output)
+	}
+}
@@ -66,56 +66,13 @@ func runCheckCmd(cmd *cobra.Command, args []string) {
 		taskPrefix = args[1]
 	}
 
-	// Get all Todoist data
-	todoistData := util.GetTodoistData(ConfigValue.Token)
-	projects := todoistData.Projects
-
-	// Resolve project path to project ID
-	var projectID string
-	parts := strings.Split(projectPath, "/")
-
-	if len(parts) > 1 {
-		// Nested project path
-		projectID = util.GetProjectIDByPathFromProjects(projectPath, projects)
-	} else {
-		// Single project name - find root project
-		projectName := parts[0]
-		for _, proj := range projects {
-			if strings.EqualFold(proj.Name, projectName) && proj.ParentID == "" {
-				projectID = proj.ID
-				break
-			}
-		}
-	}
-
-	if projectID == "" {
-		util.Die(fmt.Sprintf("Project not found: %s", projectPath), nil)
-	}
-
-	// Find tasks matching the prefix
-	matches := util.FindTasksByPrefix(projectID, taskPrefix, todoistData)
-
-	if len(matches) == 0 {
-		util.Die(fmt.Sprintf("No incomplete tasks found matching '%s' in project '%s'", taskPrefix, projectPath), nil)
-	}
-
-	if len(matches) > 1 {
-		// Multiple matches - show error with list
-		fmt.Printf("Error: Multiple tasks match '%s' in project '%s':\n\n", taskPrefix, projectPath)
-		for i, task := range matches {
-			status := "incomplete"
-			if task.CompletedAt != "" {
-				status = "completed"
-			}
-			fmt.Printf("  %d. [%s] %s (ID: %s)\n", i+1, status, task.Content, task.ID)
-		}
-		fmt.Println("\nPlease provide a more specific task prefix to match exactly one task.")
-		util.Die("", nil)
+	// Find the task, by prefix match
+	todoistData := util.GetTodoistData(cmd.Context(), TodoistClient, util.ResourceProjects, util.ResourceItems)
+	task, err := util.FindTask(projectPath, taskPrefix, todoistData)
+	if err != nil {
+		util.Die("Failed to complete task", err)
 	}
 
-	// Single match - complete the task
-	task := matches[0]
-
 	// Check if already completed (silently succeed)
 	if task.CompletedAt != "" {
 		// Idempotent behavior - task already done
@@ -123,11 +80,18 @@ func runCheckCmd(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	// Complete the task
-	err := util.CompleteTask(ConfigValue.Token, task.ID)
+	// Complete the task, or queue it if offline
+	batch := util.NewCommandBatch()
+	batch.ItemClose(task.ID)
+	summary := fmt.Sprintf("complete task '%s'", task.Content)
+	queued, err := TodoistClient.CommitOrQueue(cmd.Context(), batch, summary)
 	if err != nil {
 		util.Die(fmt.Sprintf("Failed to complete task '%s'", task.Content), err)
 	}
 
+	if queued {
+		printQueued(summary)
+		return
+	}
 	fmt.Printf("✓ Completed: %s\n", task.Content)
 }
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/layfellow/todoister/util"
+)
+
+func TestClientUsesBaseURL(t *testing.T) {
+	var gotPath, gotAuth, gotAgent string
+
+	// Local stand-in for the Todoist API
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotAgent = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(util.TaskResponse{ID: "42", Content: "Test Task", ProjectID: "1"})
+	}))
+	defer server.Close()
+
+	config := util.ConfigType{Token: "secret"}
+	config.URL = server.URL + "/api/v1/"
+	client := util.NewClient(&config, "1.2.3")
+
+	task, err := client.CreateTask(context.Background(), "Test Task", "1", nil, "")
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	if task.ID != "42" {
+		t.Errorf("Expected task ID '42', got '%s'", task.ID)
+	}
+	if gotPath != "/api/v1/tasks" {
+		t.Errorf("Expected path '/api/v1/tasks', got '%s'", gotPath)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Expected bearer token, got '%s'", gotAuth)
+	}
+	if gotAgent != "todoister/1.2.3" {
+		t.Errorf("Expected user agent 'todoister/1.2.3', got '%s'", gotAgent)
+	}
+}
+
+func TestClientDefaultBaseURL(t *testing.T) {
+	client := util.NewClient(&util.ConfigType{}, "DEV")
+	if client.BaseURL != util.DefaultBaseURL {
+		t.Errorf("Expected default base URL '%s', got '%s'", util.DefaultBaseURL, client.BaseURL)
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/layfellow/todoister/util"
+)
+
+func TestCommandBatchCommit(t *testing.T) {
+	var received []util.SyncCommand
+
+	// Stand-in Sync API: accepts project_add, rejects everything else
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.Unmarshal([]byte(r.FormValue("commands")), &received); err != nil {
+			t.Errorf("Failed to parse commands: %v", err)
+		}
+		status := make(map[string]interface{})
+		mapping := make(map[string]string)
+		for _, c := range received {
+			if c.Type == "project_add" {
+				status[c.UUID] = "ok"
+				mapping[c.TempID] = "6X7rM8997g3RQmvh"
+			} else {
+				status[c.UUID] = map[string]interface{}{"error_code": 15, "error": "Invalid temporary id"}
+			}
+		}
+		_, _ = fmt.Fprintf(w, `{"sync_token":"abc","sync_status":%s,"temp_id_mapping":%s}`,
+			mustJSON(t, status), mustJSON(t, mapping))
+	}))
+	defer server.Close()
+
+	config := util.ConfigType{Token: "secret"}
+	config.URL = server.URL
+	client := util.NewClient(&config, "DEV")
+
+	batch := util.NewCommandBatch()
+	projectTempID := batch.ProjectAdd(map[string]interface{}{"name": "Shopping"})
+	batch.ItemAdd(map[string]interface{}{"content": "Buy milk", "project_id": projectTempID})
+
+	results, err := client.Commit(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 commands in one request, got %d", len(received))
+	}
+	if received[1].Args["project_id"] != projectTempID {
+		t.Errorf("Expected item_add to reference temp_id %s, got %v", projectTempID, received[1].Args["project_id"])
+	}
+	if results.SyncToken != "abc" {
+		t.Errorf("Expected sync token 'abc', got '%s'", results.SyncToken)
+	}
+	if results.Results[0].Err != nil || results.Results[0].ID != "6X7rM8997g3RQmvh" {
+		t.Errorf("Expected project_add to succeed with real ID, got %+v", results.Results[0])
+	}
+	if results.ResolveID(projectTempID) != "6X7rM8997g3RQmvh" {
+		t.Errorf("Expected temp_id to resolve to real ID, got '%s'", results.ResolveID(projectTempID))
+	}
+	if results.Results[1].Err == nil {
+		t.Error("Expected item_add to fail")
+	}
+	if results.Err() == nil {
+		t.Error("Expected batch error when a command fails")
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	return string(b)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/layfellow/todoister/util"
+)
+
+func TestDebugRedactsToken(t *testing.T) {
+	const token = "0123456789abcdef0123456789abcdef01234567"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"sync_token":"abc","sync_status":{},"temp_id_mapping":{}}`)
+	}))
+	defer server.Close()
+
+	// Capture stderr and the log file
+	logfile := filepath.Join(t.TempDir(), "out.log")
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	util.InitLogger(logfile, true)
+	t.Cleanup(func() { util.InitLogger(logfile, false) })
+
+	config := util.ConfigType{Token: token, Debug: true}
+	config.URL = server.URL
+	client := util.NewClient(&config, "DEV")
+	batch := util.NewCommandBatch()
+	batch.ItemClose("6X7rM8997g3RQmvh")
+	_, err := client.Commit(context.Background(), batch)
+
+	_ = w.Close()
+	os.Stderr = oldStderr
+	var stderr bytes.Buffer
+	_, _ = io.Copy(&stderr, r)
+
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	logged, err := os.ReadFile(logfile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+
+	for name, output := range map[string]string{"stderr": stderr.String(), "log file": string(logged)} {
+		if strings.Contains(output, token) {
+			t.Errorf("Token leaked to %s:\n%s", name, output)
+		}
+		for _, want := range []string{"method=POST", "/sync", "item_close", "Bearer REDACTED", "status=200", "size=", "latency="} {
+			if !strings.Contains(output, want) {
+				t.Errorf("Expected %q in %s:\n%s", want, name, output)
+			}
+		}
+	}
+}
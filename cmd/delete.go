@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -11,7 +12,7 @@ import (
 )
 
 const (
-	deleteLong = `Delete a resource from Todoist (currently supports: project, task).
+	deleteLong = `Delete a resource from Todoist (currently supports: project, task, reminder).
 `
 
 	deleteProjectLong = `Delete a project from Todoist.
@@ -67,6 +68,26 @@ todoister delete task -p Work/Reports 'Create monthly report'
 # Delete task without confirmation:
 todoister delete task -f -p Personal 'Buy groceries'
 todoister rm task --force '#Work' 'Old task'`
+
+	deleteReminderLong = `Delete a reminder of a task.
+
+Use <code>#[PARENT/SUBPARENT.../]PROJECT</code> to specify the project name of the task, or the
+<code>--project</code> flag, as for <code>delete task</code>. You can identify a <code>TASK</code>
+by its partial name.
+
+If the task has more than one reminder, use <code>--at</code> or <code>--before</code> to
+identify the one to delete, as given to <code>add reminder</code>; <code>todoister reminders --all</code>
+lists them.
+`
+
+	deleteReminderExample = `# Delete the only reminder of task Submit report of project Work:
+todoister delete reminder '#Work' 'Submit report'
+
+# Delete its reminder one hour before it is due:
+todoister delete reminder -p Work 'Submit' --before 60
+
+# Delete its reminder on January 15 at 9:00, without confirmation:
+todoister rm reminder -f -p Work 'Submit' --at '2026-01-15 09:00'`
 )
 
 var (
@@ -74,6 +95,38 @@ var (
 	deleteProjectFlag string
 )
 
+// confirm asks a yes/no question on the terminal.
+//   - ctx: the command context; cancelling it (e.g., with Ctrl-C) aborts the prompt
+//   - prompt: the question to ask
+//
+// Returns true only if the user answers "y".
+func confirm(ctx context.Context, prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+
+	type answer struct {
+		response string
+		err      error
+	}
+	answers := make(chan answer, 1)
+	go func() {
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		answers <- answer{response, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		fmt.Println()
+		util.Die("Aborted", ctx.Err())
+	case a := <-answers:
+		if a.err != nil {
+			util.Die("Failed to read input", a.err)
+		}
+		return strings.ToLower(strings.TrimSpace(a.response)) == "y"
+	}
+	return false
+}
+
 var deleteProjectCmd = &cobra.Command{
 	Use:     "project [flags] [PARENT/.../]NAME",
 	Short:   "Delete a project",
@@ -84,32 +137,33 @@ var deleteProjectCmd = &cobra.Command{
 		path := args[0]
 
 		// Fetch Todoist data and find the project ID
-		todoistData := util.GetTodoistData(ConfigValue.Token)
+		todoistData := util.GetTodoistData(cmd.Context(), TodoistClient, util.ResourceProjects)
 		projectID := util.GetProjectIDByPathFromProjects(path, todoistData.Projects)
 
 		if projectID == "" {
-			util.Die(fmt.Sprintf("Project '%s' not found", path), nil)
+			util.Die(fmt.Sprintf("Project '%s'", path), util.ErrNotFound)
 		}
 
 		// Unless --force is set, prompt for confirmation
 		if !forceDelete {
-			fmt.Printf("Delete project '%s' and all its descendants? [y/N]: ", path)
-			reader := bufio.NewReader(os.Stdin)
-			response, err := reader.ReadString('\n')
-			if err != nil {
-				util.Die("Failed to read input", err)
-			}
-			if strings.ToLower(strings.TrimSpace(response)) != "y" {
+			if !confirm(cmd.Context(), fmt.Sprintf("Delete project '%s' and all its descendants?", path)) {
 				return
 			}
 		}
 
-		// Delete the project
-		err := util.DeleteProject(ConfigValue.Token, projectID)
+		// Delete the project, or queue it if offline
+		batch := util.NewCommandBatch()
+		batch.ProjectDelete(projectID)
+		summary := fmt.Sprintf("delete project '%s'", path)
+		queued, err := TodoistClient.CommitOrQueue(cmd.Context(), batch, summary)
 		if err != nil {
 			util.Die("Failed to delete project", err)
 		}
 
+		if queued {
+			printQueued(summary)
+			return
+		}
 		fmt.Printf("Deleted project '%s'\n", path)
 	},
 }
@@ -143,70 +197,130 @@ var deleteTaskCmd = &cobra.Command{
 			projectPath = strings.TrimPrefix(projectPath, "#")
 		}
 
-		// Parse the project path to extract parent and project name
-		parts := strings.Split(projectPath, "/")
-		projectName := parts[len(parts)-1]
-		var projectID string
+		// Fetch Todoist data and find the task, by prefix match
+		todoistData := util.GetTodoistData(cmd.Context(), TodoistClient, util.ResourceProjects, util.ResourceItems)
+		task, err := util.FindTask(projectPath, taskContent, todoistData)
+		if err != nil {
+			util.Die("Failed to delete task", err)
+		}
 
-		// Fetch Todoist data and find the project ID
-		todoistData := util.GetTodoistData(ConfigValue.Token)
-		projects := todoistData.Projects
+		// Unless --force is set, prompt for confirmation
+		if !forceDelete {
+			if !confirm(cmd.Context(), fmt.Sprintf("Delete task '%s'?", task.Content)) {
+				return
+			}
+		}
+
+		// Delete the task, or queue it if offline
+		batch := util.NewCommandBatch()
+		batch.ItemDelete(task.ID)
+		summary := fmt.Sprintf("delete task '%s'", task.Content)
+		queued, err := TodoistClient.CommitOrQueue(cmd.Context(), batch, summary)
+		if err != nil {
+			util.Die("Failed to delete task", err)
+		}
+
+		if queued {
+			printQueued(summary)
+			return
+		}
+		fmt.Printf("Deleted task '%s'\n", task.Content)
+	},
+}
+
+var deleteReminderCmd = &cobra.Command{
+	Use:     "reminder [flags] [#][PARENT/.../PROJECT] TASK",
+	Short:   "Delete a reminder of a task",
+	Long:    deleteReminderLong,
+	Example: deleteReminderExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if deleteProjectFlag != "" && len(args) != 1 {
+			return fmt.Errorf("when using --project flag, only TASK is required")
+		} else if deleteProjectFlag == "" && len(args) != 2 {
+			return fmt.Errorf("expected PROJECT_PATH and TASK, or use --project flag")
+		}
+		if reminderAt != "" && reminderBefore != "" {
+			return fmt.Errorf("expected either --at or --before, not both")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		projectPath, taskPrefix := deleteProjectFlag, args[0]
+		if deleteProjectFlag == "" {
+			projectPath, taskPrefix = strings.TrimPrefix(args[0], "#"), args[1]
+		}
+
+		todoistData := util.GetTodoistData(cmd.Context(), TodoistClient,
+			util.ResourceProjects, util.ResourceItems, util.ResourceReminders)
+		task, err := util.FindTask(projectPath, taskPrefix, todoistData)
+		if err != nil {
+			util.Die("Failed to delete reminder", err)
+		}
 
-		if len(parts) > 1 {
-			projectID = util.GetProjectIDByPathFromProjects(projectPath, projects)
-			if projectID == "" {
-				util.Die(fmt.Sprintf("Project '%s' not found", projectPath), nil)
+		// Find the reminders of the task that --at or --before select
+		var at *util.DateParams
+		if reminderAt != "" {
+			if at, err = util.ParseDateInput(reminderAt); err != nil || at.DueDateTime == "" {
+				util.Die(fmt.Sprintf("Invalid --at, expected YYYY-MM-DD HH:MM: %s", reminderAt), err)
 			}
-		} else {
-			for _, proj := range projects {
-				if strings.EqualFold(proj.Name, projectName) && proj.ParentID == "" {
-					projectID = proj.ID
-					break
+		}
+		before := -1
+		if reminderBefore != "" {
+			before = parseMinutes(reminderBefore)
+		}
+		matches := make([]util.TodoistReminder, 0)
+		for _, reminder := range todoistData.Reminders {
+			if reminder.ItemID != task.ID {
+				continue
+			}
+			if at != nil {
+				when, ok := util.ReminderTime(reminder, task)
+				if reminder.Type != util.ReminderAbsolute || !ok || when.Format("2006-01-02T15:04") != at.DueDateTime[:16] {
+					continue
 				}
 			}
-			if projectID == "" {
-				util.Die(fmt.Sprintf("Root-level project '%s' not found", projectName), nil)
+			if before >= 0 && (reminder.Type != util.ReminderRelative || reminder.MinuteOffset != before) {
+				continue
 			}
+			matches = append(matches, reminder)
 		}
 
-		// Find matching tasks using prefix match
-		matches := util.FindTasksByPrefix(projectID, taskContent, todoistData)
-
 		if len(matches) == 0 {
-			util.Die(fmt.Sprintf("Task '%s' not found in project '%s'", taskContent, projectPath), nil)
+			util.Die(fmt.Sprintf("Reminder of task '%s'", task.Content), util.ErrNotFound)
 		}
-
 		if len(matches) > 1 {
-			msg := fmt.Sprintf("Multiple tasks match '%s':\n", taskContent)
-			for _, task := range matches {
-				msg += fmt.Sprintf("  - %s\n", task.Content)
+			list := ""
+			for _, reminder := range matches {
+				list += fmt.Sprintf("\n  - %s", describeReminder(reminder, task))
 			}
-			msg += "Please provide a more specific task name."
-			util.Die(msg, nil)
+			util.Die(fmt.Sprintf("Reminder of task '%s'", task.Content),
+				fmt.Errorf("%w, please use --at or --before to choose one of:%s", util.ErrAmbiguous, list))
 		}
 
-		task := matches[0]
+		reminder := matches[0]
+		when := describeReminder(reminder, task)
 
 		// Unless --force is set, prompt for confirmation
 		if !forceDelete {
-			fmt.Printf("Delete task '%s'? [y/N]: ", task.Content)
-			reader := bufio.NewReader(os.Stdin)
-			response, err := reader.ReadString('\n')
-			if err != nil {
-				util.Die("Failed to read input", err)
-			}
-			if strings.ToLower(strings.TrimSpace(response)) != "y" {
+			if !confirm(cmd.Context(), fmt.Sprintf("Delete reminder of task '%s' %s?", task.Content, when)) {
 				return
 			}
 		}
 
-		// Delete the task
-		err := util.DeleteTask(ConfigValue.Token, task.ID)
+		// Delete the reminder, or queue it if offline
+		batch := util.NewCommandBatch()
+		batch.ReminderDelete(reminder.ID)
+		summary := fmt.Sprintf("delete reminder of task '%s'", task.Content)
+		queued, err := TodoistClient.CommitOrQueue(cmd.Context(), batch, summary)
 		if err != nil {
-			util.Die("Failed to delete task", err)
+			util.Die("Failed to delete reminder", err)
 		}
 
-		fmt.Printf("Deleted task '%s'\n", task.Content)
+		if queued {
+			printQueued(summary)
+			return
+		}
+		fmt.Printf("Deleted reminder of task '%s' %s\n", task.Content, when)
 	},
 }
 
@@ -228,8 +342,19 @@ func init() {
 		"skip confirmation prompt")
 	deleteTaskCmd.SetHelpFunc(util.CustomHelpFunc)
 
+	deleteReminderCmd.Flags().StringVarP(&deleteProjectFlag, "project", "p", "",
+		"project name or path (e.g., 'Work' or 'Work/Reports')")
+	deleteReminderCmd.Flags().BoolVarP(&forceDelete, "force", "f", false,
+		"skip confirmation prompt")
+	deleteReminderCmd.Flags().StringVar(&reminderAt, "at", "",
+		"delete the reminder at date and time <string> (YYYY-MM-DD HH:MM)")
+	deleteReminderCmd.Flags().StringVar(&reminderBefore, "before", "",
+		"delete the reminder <string> minutes, or a duration like 1h30m, before the task is due")
+	deleteReminderCmd.SetHelpFunc(util.CustomHelpFunc)
+
 	deleteCmd.AddCommand(deleteProjectCmd)
 	deleteCmd.AddCommand(deleteTaskCmd)
+	deleteCmd.AddCommand(deleteReminderCmd)
 	deleteCmd.SetHelpFunc(util.CustomHelpFunc)
 
 	RootCmd.AddCommand(deleteCmd)
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/layfellow/todoister/util"
@@ -176,3 +177,39 @@ func TestDeleteTaskFindByPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestFindTask(t *testing.T) {
+	todoistData := &util.TodoistData{
+		Projects: []util.TodoistProject{
+			{Project: util.Project{Name: "Work"}, ID: "100"},
+			{Project: util.Project{Name: "Reports"}, ID: "101", ParentID: "100"},
+			{Project: util.Project{Name: "Reports"}, ID: "102"},
+		},
+		Items: []util.TodoistItem{
+			{Task: util.Task{Content: "Buy milk"}, ID: "1", ProjectID: "100"},
+			{Task: util.Task{Content: "Buy coffee"}, ID: "2", ProjectID: "100"},
+			{Task: util.Task{Content: "Q4 summary"}, ID: "3", ProjectID: "101"},
+		},
+	}
+	tests := []struct {
+		name       string
+		path       string
+		prefix     string
+		expectedID string
+		err        error
+	}{
+		{"root project", "work", "buy m", "1", nil},
+		{"nested project", "Work/Reports", "Q4", "3", nil},
+		{"root project of the same name", "Reports", "Q4", "", util.ErrNotFound},
+		{"unknown project", "Home", "Buy", "", util.ErrNotFound},
+		{"several tasks", "Work", "Buy", "", util.ErrAmbiguous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := util.FindTask(tt.path, tt.prefix, todoistData)
+			if task.ID != tt.expectedID || !errors.Is(err, tt.err) {
+				t.Errorf("Expected task %q and error %v, got %q and %v", tt.expectedID, tt.err, task.ID, err)
+			}
+		})
+	}
+}
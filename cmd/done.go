@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/layfellow/todoister/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	doneLong = `List completed tasks.
+
+- <code>NAME</code> is the name of a project to list completed tasks from, including its subprojects.
+You can specify a project name by its full path, e.g., <code>Work/Project</code>.
+Without it, completed tasks from all projects are listed.
+
+Todoist only returns active tasks when syncing, so completed tasks are fetched separately
+and kept in a local archive, <code>completed.pb</code>, next to the cache.
+<code>--since</code> and <code>--until</code> take a date as <code>YYYY-MM-DD</code>.
+`
+
+	doneExample = `# List the tasks completed in the last 7 days:
+todoister done
+
+# List the tasks completed in project Work since January 1:
+todoister done --since 2025-01-01 Work`
+)
+
+var sinceFlag string
+var untilFlag string
+
+// parseDay parses a YYYY-MM-DD date flag in local time, exiting with an error if invalid.
+//   - name: the flag name
+//   - value: the flag value
+//
+// Returns the start of the day, or the zero time if value is empty.
+func parseDay(name, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		util.Die(fmt.Sprintf("Invalid --%s date, expected YYYY-MM-DD: %s", name, value), err)
+	}
+	return day
+}
+
+var doneCmd = &cobra.Command{
+	Use:     "done [flags] [NAME]",
+	Short:   "List completed tasks",
+	Long:    doneLong,
+	Example: doneExample,
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		since := parseDay("since", sinceFlag)
+		if since.IsZero() {
+			now := time.Now()
+			since = time.Date(now.Year(), now.Month(), now.Day()-7, 0, 0, 0, 0, time.Local)
+		}
+		until := parseDay("until", untilFlag)
+		if !until.IsZero() {
+			// Include the whole day
+			until = until.AddDate(0, 0, 1).Add(-time.Second)
+		}
+
+		todoistData := util.GetTodoistData(cmd.Context(), TodoistClient, util.ResourceProjects)
+		paths := util.ProjectPaths(todoistData.Projects)
+		prefix := ""
+		if len(args) > 0 {
+			projectID, projectPath := util.GetProjectIDByPath(args[0], todoistData)
+			if projectID == "" {
+				util.Die(fmt.Sprintf("Project '%s'", args[0]), util.ErrNotFound)
+			}
+			prefix = strings.ToLower(projectPath)
+		}
+
+		day, count := "", 0
+		for _, task := range util.GetCompletedTasks(cmd.Context(), TodoistClient, since, until) {
+			path := paths[task.ProjectID]
+			lowerPath := strings.ToLower(path)
+			if prefix != "" && lowerPath != prefix && !strings.HasPrefix(lowerPath, prefix+"/") {
+				continue
+			}
+			count++
+			if completedAt, err := time.Parse(time.RFC3339, task.CompletedAt); err == nil {
+				if d := completedAt.Local().Format("Mon, Jan 2, 2006"); d != day {
+					day = d
+					fmt.Printf("\n# %s\n\n", day)
+				}
+			}
+			if path == "" || lowerPath == prefix {
+				fmt.Printf("  ✓ %s\n", task.Content)
+			} else {
+				fmt.Printf("  ✓ %s (%s)\n", task.Content, path)
+			}
+		}
+		if count == 0 {
+			fmt.Println("No completed tasks")
+		}
+	},
+}
+
+func init() {
+	doneCmd.Flags().StringVar(&sinceFlag, "since", "",
+		"list tasks completed on or after date <string> (default is 7 days ago)")
+	doneCmd.Flags().StringVar(&untilFlag, "until", "",
+		"list tasks completed on or before date <string> (default is today)")
+	doneCmd.SetHelpFunc(util.CustomHelpFunc)
+
+	RootCmd.AddCommand(doneCmd)
+}
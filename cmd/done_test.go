@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompletedArchive(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	now := time.Now().UTC()
+	completed := []struct {
+		content string
+		at      time.Time
+	}{
+		{"Send invoice", now.Add(-20 * 24 * time.Hour)},
+		{"Call printer", now.Add(-2 * 24 * time.Hour)},
+		{"Order toner", now.Add(-time.Hour)},
+	}
+
+	// Serves one completed task per page, filtered by the since and until parameters
+	var windows []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/tasks/completed/by_completion_date") {
+			_, _ = io.WriteString(w, standInSyncResponse)
+			return
+		}
+		since, _ := time.Parse(time.RFC3339, r.FormValue("since"))
+		until, _ := time.Parse(time.RFC3339, r.FormValue("until"))
+		start, _ := strconv.Atoi(r.FormValue("cursor"))
+		if start == 0 {
+			windows = append(windows, fmt.Sprintf("%s..%s", r.FormValue("since"), r.FormValue("until")))
+		}
+		for i := start; i < len(completed); i++ {
+			if completed[i].at.Before(since) || completed[i].at.After(until) {
+				continue
+			}
+			_, _ = fmt.Fprintf(w, `{"items":[{"id":"c%d","project_id":"6Jf8VQXxpwv56VQ7","content":%q,"completed_at":%q}],
+				"next_cursor":"%d"}`, i, completed[i].content, completed[i].at.Format(time.RFC3339), i+1)
+			return
+		}
+		_, _ = io.WriteString(w, `{"items":[],"next_cursor":null}`)
+	}))
+	defer server.Close()
+	ConfigValue.URL = server.URL + "/api/v1"
+
+	output := runCommand(t, "-t", "test", "done")
+	if strings.Contains(output, "Send invoice") || !strings.Contains(output, "  ✓ Call printer (Work)\n") ||
+		!strings.Contains(output, "  ✓ Order toner (Work)\n") {
+		t.Errorf("Unexpected output for the last 7 days:\n%s", output)
+	}
+	if len(windows) != 1 {
+		t.Errorf("Expected a single span fetched, got %v", windows)
+	}
+
+	// The archive starts 7 days ago, so the next listing only fetches the tasks completed
+	// before that, and since its last update
+	windows = nil
+	since := now.AddDate(0, 0, -25).Format("2006-01-02")
+	output = runCommand(t, "-t", "test", "done", "--since", since, "Work")
+	if !strings.Contains(output, "  ✓ Send invoice\n") || !strings.Contains(output, "  ✓ Order toner\n") {
+		t.Errorf("Unexpected output since %s:\n%s", since, output)
+	}
+	if strings.Count(output, "✓") != 3 {
+		t.Errorf("Expected each completed task once:\n%s", output)
+	}
+	if len(windows) != 2 {
+		t.Errorf("Expected two spans fetched, got %v", windows)
+	}
+
+	runCommand(t, "-t", "test", "tasks", "Work")
+	windows = nil
+	path := filepath.Join(t.TempDir(), "backup.json")
+	runCommand(t, "-t", "test", "--offline", "export", "--include-completed", path)
+	if len(windows) != 0 {
+		t.Errorf("Expected no requests offline, got %v", windows)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected an export: %v", err)
+	}
+	for _, c := range completed {
+		if !strings.Contains(string(data), `"content": "`+c.content+`"`) {
+			t.Errorf("Expected %s in the export", c.content)
+		}
+	}
+	if !strings.Contains(string(data), `"content": "Write report"`) {
+		t.Error("Expected the active tasks in the export too")
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/layfellow/todoister/util"
+)
+
+func TestAPIErrorExitCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		sentinel error
+		exitCode int
+	}{
+		{
+			name:     "expired token",
+			status:   401,
+			body:     `{"error":"Unauthorized","error_code":401,"error_tag":"AUTH_INVALID_TOKEN","http_code":401}`,
+			sentinel: util.ErrAuth,
+			exitCode: util.ExitAuth,
+		},
+		{
+			name:     "missing project",
+			status:   404,
+			body:     `{"error":"Project not found","error_code":478,"error_tag":"NOT_FOUND","http_code":404}`,
+			sentinel: util.ErrNotFound,
+			exitCode: util.ExitNotFound,
+		},
+		{
+			name:     "rate limited",
+			status:   429,
+			body:     `{"error":"Too many requests","error_code":35,"error_tag":"LIMITS_REACHED","http_code":429}`,
+			sentinel: util.ErrRateLimited,
+			exitCode: util.ExitRateLimited,
+		},
+		{
+			name:     "plain text error",
+			status:   400,
+			body:     "Bad request",
+			exitCode: util.ExitFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			config := util.ConfigType{Token: "secret"}
+			config.URL = server.URL
+			config.MaxAttempts = 1
+			client := util.NewClient(&config, "DEV")
+
+			_, err := client.CreateProject(context.Background(), "Shopping", "", "")
+
+			var apiErr *util.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected *util.APIError, got %T: %v", err, err)
+			}
+			if apiErr.HTTPCode != tt.status {
+				t.Errorf("Expected HTTP code %d, got %d", tt.status, apiErr.HTTPCode)
+			}
+			if tt.sentinel != nil && !errors.Is(err, tt.sentinel) {
+				t.Errorf("Expected error to match %v", tt.sentinel)
+			}
+			if code := util.ExitCode(err); code != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, code)
+			}
+		})
+	}
+}
+
+func TestNetworkErrorExitCode(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close() // Nothing listens on this address anymore
+
+	config := util.ConfigType{Token: "secret"}
+	config.URL = server.URL
+	config.MaxAttempts = 2
+	config.InitialBackoff = time.Millisecond
+	client := util.NewClient(&config, "DEV")
+
+	_, err := client.CreateProject(context.Background(), "Shopping", "", "")
+	if !errors.Is(err, util.ErrNetwork) {
+		t.Errorf("Expected network error, got %v", err)
+	}
+	if code := util.ExitCode(err); code != util.ExitNetwork {
+		t.Errorf("Expected exit code %d, got %d", util.ExitNetwork, code)
+	}
+}
+
+func TestContextTimeout(t *testing.T) {
+	var attempts atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		// Hold the request until the client gives up
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := util.ConfigType{Token: "secret"}
+	config.URL = server.URL
+	client := util.NewClient(&config, "DEV")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.CreateProject(ctx, "Shopping", "", "")
+	if code := util.ExitCode(err); code != util.ExitTimeout {
+		t.Errorf("Expected exit code %d, got %d (%v)", util.ExitTimeout, code, err)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("Expected no retries after the deadline, got %d attempts", n)
+	}
+}
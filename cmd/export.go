@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"strings"
+	"time"
 
 	"github.com/layfellow/todoister/util"
 	"github.com/spf13/cobra"
@@ -11,6 +12,10 @@ const (
 	exportLong = `Export all Todoist projects as a tree of JSON or YAML files.
 
 - <code>PATH</code> is a file or directory where to export the projects, by default <code>index.json</code>.
+
+With <code>--include-completed</code>, the tasks in the local archive of completed tasks are exported
+along with the active ones, in their projects, with their <code>completed_at</code> date.
+See <code>todoister done</code>.
 `
 
 	exportExample = `# Export to a single index.json file in the current directory:
@@ -23,12 +28,16 @@ todoister export ~/todoist.json
 todoister export --yaml ~/todoist.yaml
 
 # Export to a projects directory in the home, with subdirectories down to 3 levels deep:
-todoister export --json -d 3 ~/projects`
+todoister export --json -d 3 ~/projects
+
+# Export a complete backup, including completed tasks:
+todoister export --include-completed ~/backup.json`
 )
 
 var useJSON bool
 var useYAML bool
 var depth int
+var includeCompleted bool
 
 var exportCmd = &cobra.Command{
 	Use:     "export [flags] [PATH]",
@@ -64,7 +73,23 @@ var exportCmd = &cobra.Command{
 			exportPath = args[0]
 		}
 
-		hierarchicalData := util.HierarchicalData(util.GetTodoistData(ConfigValue.Token))
+		todoistData := util.GetTodoistData(cmd.Context(), TodoistClient, util.AllResources...)
+		if includeCompleted {
+			// Add each completed task once, unless it is still active, e.g., a recurring task
+			active := make(map[string]bool)
+			for _, item := range todoistData.Items {
+				active[item.ID] = true
+			}
+			completed := util.GetCompletedTasks(cmd.Context(), TodoistClient, time.Time{}, time.Time{})
+			// Latest completions first
+			for i := len(completed) - 1; i >= 0; i-- {
+				if !active[completed[i].ID] {
+					active[completed[i].ID] = true
+					todoistData.Items = append(todoistData.Items, completed[i])
+				}
+			}
+		}
+		hierarchicalData := util.HierarchicalData(todoistData)
 		err := util.WriteHierarchicalData(hierarchicalData, exportFormat, depth, exportPath)
 		if err != nil {
 			util.Die("Failed to export", err)
@@ -79,6 +104,8 @@ func init() {
 		"export in YAML format")
 	exportCmd.Flags().IntVarP(&depth, "depth", "d", -1,
 		"depth of subdirectory tree to create on the filesystem when exporting\n(default is 0, i.e., no subdirectories)")
+	exportCmd.Flags().BoolVar(&includeCompleted, "include-completed", false,
+		"include the completed tasks in the local archive, see the done command")
 	exportCmd.SetHelpFunc(util.CustomHelpFunc)
 
 	RootCmd.AddCommand(exportCmd)
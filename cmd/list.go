@@ -46,7 +46,8 @@ var listCmd = &cobra.Command{
 	Long:    listLong,
 	Example: listExample,
 	Run: func(cmd *cobra.Command, args []string) {
-		projectData := util.HierarchicalData(util.GetTodoistData(ConfigValue.Token))
+		projectData := util.HierarchicalData(util.GetTodoistData(cmd.Context(), TodoistClient,
+			util.ResourceProjects))
 		project := util.ExportedProject{Subprojects: projectData}
 		project.Name = "Projects"
 
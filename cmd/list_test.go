@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/layfellow/todoister/util"
@@ -94,7 +95,7 @@ func TestTasksCommand(t *testing.T) {
 	// Get the Beta project and print its tasks
 	if actualPathname, p := util.GetProjectByPathName("Beta", &project); p != nil && p.Tasks != nil {
 		fmt.Printf("\n# %s\n\n", actualPathname)
-		printTasks(p.Tasks)
+		printTasks(p.Tasks, 0)
 	}
 
 	// Restore stdout and read captured output
@@ -117,3 +118,103 @@ func TestTasksCommand(t *testing.T) {
 		t.Errorf("Tasks output mismatch.\nExpected:\n%q\nActual:\n%q", expected, actual)
 	}
 }
+
+func TestTasksCommandSubtasks(t *testing.T) {
+	// Add a subtask tree under Beta item 1
+	testData := createTestData()
+	testData.Items = append(testData.Items,
+		util.TodoistItem{Task: util.Task{Content: "Beta subtask"}, ID: "task3", ProjectID: "2", ParentID: "task1"},
+		util.TodoistItem{Task: util.Task{Content: "Beta sub-subtask", Description: "Details"},
+			ID: "task4", ProjectID: "2", ParentID: "task3"},
+	)
+	hierarchicalData := util.HierarchicalData(testData)
+
+	project := util.ExportedProject{Subprojects: hierarchicalData}
+	project.Name = "Projects"
+	_, p := util.GetProjectByPathName("Beta", &project)
+	if p == nil || len(p.Tasks) != 2 || len(p.Tasks[0].Subtasks) != 1 || len(p.Tasks[0].Subtasks[0].Subtasks) != 1 {
+		t.Fatalf("Expected subtasks nested under their parents, got %+v", p)
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	printTasks(p.Tasks, 0)
+
+	// Restore stdout and read captured output
+	_ = w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+
+	expected := `  - Beta item 1
+    - Beta subtask
+      - Beta sub-subtask
+        Details
+
+  - Beta item 2
+`
+
+	actual := buf.String()
+
+	if actual != expected {
+		t.Errorf("Tasks output mismatch.\nExpected:\n%q\nActual:\n%q", expected, actual)
+	}
+}
+
+func TestHierarchicalDataOrder(t *testing.T) {
+	// Resources in sync order, which is not the order the Todoist UI shows them in
+	testData := &util.TodoistData{
+		Projects: []util.TodoistProject{
+			{Project: util.Project{Name: "Beta"}, ID: "2", ChildOrder: 2},
+			{Project: util.Project{Name: "Beta child 2"}, ID: "4", ParentID: "2", ChildOrder: 2},
+			{Project: util.Project{Name: "Alpha"}, ID: "1", ChildOrder: 1},
+			{Project: util.Project{Name: "Beta child 1"}, ID: "3", ParentID: "2", ChildOrder: 1},
+			{Project: util.Project{Name: "Inbox", InboxProject: true}, ID: "5", ChildOrder: 3},
+		},
+		Sections: []util.TodoistSection{
+			{Section: util.Section{Name: "Later"}, ID: "s2", ProjectID: "1", Order: 2},
+			{Section: util.Section{Name: "Now"}, ID: "s1", ProjectID: "1", Order: 1},
+		},
+		Items: []util.TodoistItem{
+			{Task: util.Task{Content: "Second", ChildOrder: 2}, ID: "t2", ProjectID: "1"},
+			{Task: util.Task{Content: "Second subtask", ChildOrder: 2}, ID: "t4", ProjectID: "1", ParentID: "t1"},
+			{Task: util.Task{Content: "First", ChildOrder: 1}, ID: "t1", ProjectID: "1"},
+			{Task: util.Task{Content: "First subtask", ChildOrder: 1}, ID: "t3", ProjectID: "1", ParentID: "t1"},
+			// Same child_order, e.g., added offline, ordered by ID
+			{Task: util.Task{Content: "Tied b", ChildOrder: 3}, ID: "t6", ProjectID: "1"},
+			{Task: util.Task{Content: "Tied a", ChildOrder: 3}, ID: "t5", ProjectID: "1"},
+		},
+	}
+
+	var names []string
+	var walk func(projects []*util.ExportedProject)
+	walk = func(projects []*util.ExportedProject) {
+		for _, p := range projects {
+			names = append(names, p.Name)
+			walk(p.Subprojects)
+		}
+	}
+	roots := util.HierarchicalData(testData)
+	walk(roots)
+	if expected := "Inbox Alpha Beta Beta child 1 Beta child 2"; strings.Join(names, " ") != expected {
+		t.Errorf("Expected projects in order %q, got %q", expected, strings.Join(names, " "))
+	}
+
+	alpha := roots[1]
+	if len(alpha.Sections) != 2 || alpha.Sections[0].Name != "Now" || alpha.Sections[1].Name != "Later" {
+		t.Errorf("Expected sections Now, Later, got %+v", alpha.Sections)
+	}
+	var tasks []string
+	for _, task := range alpha.Tasks {
+		tasks = append(tasks, task.Content)
+		for _, subtask := range task.Subtasks {
+			tasks = append(tasks, subtask.Content)
+		}
+	}
+	if expected := "First,First subtask,Second subtask,Second,Tied a,Tied b"; strings.Join(tasks, ",") != expected {
+		t.Errorf("Expected tasks in order %q, got %q", expected, strings.Join(tasks, ","))
+	}
+}
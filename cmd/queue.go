@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/layfellow/todoister/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	queueLong = `Show the changes waiting to be sent to Todoist.
+
+Changes made with <code>--offline</code>, or while the network is unavailable, are queued
+and sent in order on the next sync, i.e., the next command run online.
+If Todoist rejects a queued change, it is held in the queue with the error
+until you retry or drop it.
+`
+
+	queueExample = `# Show the queued changes:
+todoister queue
+
+# Add a task offline, then send it:
+todoister --offline add task -p Work 'Submit report'
+todoister queue retry
+
+# Drop the second queued change:
+todoister queue drop 2`
+
+	queueRetryLong = `Send the queued changes now.
+
+<code>N</code> are the numbers of the rejected changes to send again, as shown by <code>todoister queue</code>.
+Without them, all rejected changes are sent again, along with the pending ones.
+`
+
+	queueDropLong = `Drop queued changes without sending them.
+
+<code>N</code> are the numbers of the changes to drop, as shown by <code>todoister queue</code>.
+`
+)
+
+var dropAll bool
+
+// printQueued reports a change queued for the next sync.
+func printQueued(summary string) {
+	fmt.Printf("Queued for the next sync: %s\n", summary)
+}
+
+// withQueue runs a function on the offline queue while holding the cache lock.
+//   - cmd: the running command
+//   - update: receives the queue and returns it updated, or nil to leave it unchanged
+func withQueue(cmd *cobra.Command, update func(queue []*util.QueuedCommand) []*util.QueuedCommand) {
+	lock, err := util.LockCache(cmd.Context(), util.DefaultLockTimeout)
+	if err != nil {
+		util.Die("Failed to lock cache", err)
+	}
+	defer lock.Unlock()
+
+	queue, err := util.LoadQueue()
+	if err != nil {
+		util.Die("Failed to load the queue", err)
+	}
+	if updated := update(queue); updated != nil {
+		if err := util.SaveQueue(updated); err != nil {
+			util.Die("Failed to save the queue", err)
+		}
+	}
+}
+
+// parseQueueNumbers converts the N arguments of the queue commands to a set of 0-based indices.
+//   - args: the 1-based numbers as entered by the user
+//   - length: the length of the queue
+func parseQueueNumbers(args []string, length int) map[int]bool {
+	indices := make(map[int]bool)
+	for _, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > length {
+			util.Die(fmt.Sprintf("Queued change '%s'", arg), util.ErrNotFound)
+		}
+		indices[n-1] = true
+	}
+	return indices
+}
+
+// printQueue lists the queued changes with their numbers and status.
+func printQueue(queue []*util.QueuedCommand) {
+	if len(queue) == 0 {
+		fmt.Println("No queued changes")
+		return
+	}
+	for i, entry := range queue {
+		fmt.Printf("%d. %s (queued %s)\n", i+1, entry.Summary, entry.QueuedAt.Local().Format("Jan 2, 2006, 3:04 PM"))
+		if !entry.Pending() {
+			fmt.Printf("   Rejected: %s\n", entry.Error)
+		}
+	}
+}
+
+var queueRetryCmd = &cobra.Command{
+	Use:   "retry [N...]",
+	Short: "Send the queued changes now",
+	Long:  queueRetryLong,
+	Run: func(cmd *cobra.Command, args []string) {
+		withQueue(cmd, func(queue []*util.QueuedCommand) []*util.QueuedCommand {
+			indices := parseQueueNumbers(args, len(queue))
+			for i, entry := range queue {
+				if len(indices) == 0 || indices[i] {
+					entry.Error = ""
+				}
+			}
+			return queue
+		})
+
+		if !ConfigValue.Offline {
+			// Syncing sends the queue first, so sync even if the cache is fresh
+			TodoistClient.MaxAge = 0
+			TodoistClient.NoSync = false
+			util.GetTodoistData(cmd.Context(), TodoistClient, util.ResourceProjects, util.ResourceItems)
+		}
+		withQueue(cmd, func(queue []*util.QueuedCommand) []*util.QueuedCommand {
+			printQueue(queue)
+			return nil
+		})
+	},
+}
+
+var queueDropCmd = &cobra.Command{
+	Use:   "drop [flags] N...",
+	Short: "Drop queued changes",
+	Long:  queueDropLong,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if !dropAll && len(args) == 0 {
+			return fmt.Errorf("expected the numbers of the changes to drop, or use --all flag")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		withQueue(cmd, func(queue []*util.QueuedCommand) []*util.QueuedCommand {
+			indices := parseQueueNumbers(args, len(queue))
+			kept := make([]*util.QueuedCommand, 0, len(queue))
+			for i, entry := range queue {
+				if dropAll || indices[i] {
+					fmt.Printf("Dropped: %s\n", entry.Summary)
+				} else {
+					kept = append(kept, entry)
+				}
+			}
+			return kept
+		})
+	},
+}
+
+var queueCmd = &cobra.Command{
+	Use:     "queue [command]",
+	Short:   "Show the changes waiting to be sent",
+	Long:    queueLong,
+	Example: queueExample,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		withQueue(cmd, func(queue []*util.QueuedCommand) []*util.QueuedCommand {
+			printQueue(queue)
+			return nil
+		})
+	},
+}
+
+func init() {
+	queueDropCmd.Flags().BoolVar(&dropAll, "all", false, "drop all queued changes")
+	queueRetryCmd.SetHelpFunc(util.CustomHelpFunc)
+	queueDropCmd.SetHelpFunc(util.CustomHelpFunc)
+
+	queueCmd.AddCommand(queueRetryCmd)
+	queueCmd.AddCommand(queueDropCmd)
+	queueCmd.SetHelpFunc(util.CustomHelpFunc)
+
+	RootCmd.AddCommand(queueCmd)
+}
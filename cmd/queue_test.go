@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/layfellow/todoister/util"
+)
+
+func TestOfflineQueue(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var batches [][]util.SyncCommand
+	rejectClose := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		commands := r.FormValue("commands")
+		if commands == "" {
+			_, _ = io.WriteString(w, standInSyncResponse)
+			return
+		}
+		var received []util.SyncCommand
+		if err := json.Unmarshal([]byte(commands), &received); err != nil {
+			t.Errorf("Failed to parse commands: %v", err)
+		}
+		batches = append(batches, received)
+		status := make(map[string]interface{})
+		mapping := make(map[string]string)
+		for _, c := range received {
+			if c.Type == "item_close" && rejectClose {
+				status[c.UUID] = map[string]interface{}{"error": "Item is archived", "http_code": 400}
+				continue
+			}
+			status[c.UUID] = "ok"
+			if c.TempID != "" {
+				mapping[c.TempID] = "6X7rfFVPjhvv84XX"
+			}
+		}
+		_, _ = fmt.Fprintf(w, `{"sync_token":"abc","sync_status":%s,"temp_id_mapping":%s}`,
+			mustJSON(t, status), mustJSON(t, mapping))
+	}))
+	defer server.Close()
+	online := func(args ...string) string {
+		resetGlobalFlags()
+		ConfigValue.URL = server.URL + "/api/v1"
+		return runCommand(t, append([]string{"-t", "test"}, args...)...)
+	}
+	offline := func(args ...string) string {
+		return online(append([]string{"--offline"}, args...)...)
+	}
+
+	// Fill the cache, then make changes offline
+	online("tasks", "Work")
+	if output := offline("add", "task", "-p", "Work", "Call Bob"); output != "Queued for the next sync: add task 'Call Bob' in 'Work'\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+	if output := offline("check", "-p", "Work", "Write"); output != "Queued for the next sync: complete task 'Write report'\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+	if len(batches) != 0 {
+		t.Fatalf("Expected no commands sent offline, got %v", batches)
+	}
+
+	// Queued changes are visible offline
+	if output := offline("tasks", "Work"); !strings.Contains(output, "- Call Bob") {
+		t.Errorf("Expected the queued task in the listing, got %q", output)
+	}
+	output := offline("queue")
+	if !strings.HasPrefix(output, "1. add task 'Call Bob' in 'Work'") || !strings.Contains(output, "\n2. complete task 'Write report'") {
+		t.Errorf("Unexpected queue listing: %q", output)
+	}
+
+	// The next online command sends the queue in order; the rejected change is held
+	online("tasks", "Work")
+	if len(batches) != 1 || len(batches[0]) != 2 || batches[0][0].Type != "item_add" || batches[0][1].Type != "item_close" {
+		t.Fatalf("Expected item_add and item_close in one request, got %v", batches)
+	}
+	if output := online("queue"); !strings.HasPrefix(output, "1. complete task 'Write report'") ||
+		!strings.Contains(output, "Rejected: item_close: Item is archived") {
+		t.Errorf("Unexpected queue listing: %q", output)
+	}
+
+	// Held changes are not sent again until retried
+	online("tasks", "Work")
+	if len(batches) != 1 {
+		t.Errorf("Expected the rejected change to be held, got %d requests", len(batches))
+	}
+	rejectClose = false
+	if output := online("queue", "retry"); output != "No queued changes\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+	if len(batches) != 2 || batches[1][0].UUID != batches[0][1].UUID {
+		t.Errorf("Expected the rejected change to be sent again with the same UUID, got %v", batches)
+	}
+
+	offline("delete", "task", "-f", "-p", "Work", "Buy")
+	if output := offline("queue", "drop", "1"); output != "Dropped: delete task 'Buy paper'\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+	if output := offline("queue"); output != "No queued changes\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+}
+
+func TestQueuedCreateAfterLostResponse(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+	t.Cleanup(func() { ConfigValue.MaxAttempts = 0 })
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	ConfigValue.MaxAttempts = 1
+
+	// Todoist accepts the first request to add the task, but its response is lost
+	var batches [][]util.SyncCommand
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		commands := r.FormValue("commands")
+		if commands == "" {
+			_, _ = io.WriteString(w, standInSyncResponse)
+			return
+		}
+		var received []util.SyncCommand
+		if err := json.Unmarshal([]byte(commands), &received); err != nil {
+			t.Errorf("Failed to parse commands: %v", err)
+		}
+		batches = append(batches, received)
+		if len(batches) == 1 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"sync_token":"abc","sync_status":{%q:"ok"},"temp_id_mapping":{%q:"6X7rfFVPjhvv84XX"}}`,
+			received[0].UUID, received[0].TempID)
+	}))
+	defer server.Close()
+	online := func(args ...string) string {
+		resetGlobalFlags()
+		ConfigValue.URL = server.URL + "/api/v1"
+		return runCommand(t, append([]string{"-t", "test"}, args...)...)
+	}
+
+	online("tasks", "Work")
+	if output := online("add", "task", "-p", "Work", "Call Bob"); output != "Queued for the next sync: add task 'Call Bob' in 'Work'\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+
+	// The queued task is sent again as the same command, which Todoist discards as a duplicate
+	online("tasks", "Work")
+	if len(batches) != 2 || batches[0][0].Type != "item_add" || batches[1][0].UUID != batches[0][0].UUID {
+		t.Errorf("Expected the task to be sent twice with the same UUID, got %v", batches)
+	}
+	for _, path := range paths {
+		if path != "/api/v1/sync" {
+			t.Errorf("Expected only Sync requests, got a request to %s", path)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/layfellow/todoister/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	remindersLong = `List upcoming reminders, with their tasks.
+
+- <code>NAME</code> is the name of a project to list reminders from, including its subprojects.
+You can specify a project name by its full path, e.g., <code>Work/Project</code>.
+Without it, reminders from all projects are listed.
+
+Reminders are listed by the time they fire: absolute reminders at their time, and relative
+reminders the given minutes before their task is due. Use <code>--all</code> to list past
+reminders too, and those without a time, such as location reminders.
+`
+
+	remindersExample = `# List upcoming reminders:
+todoister reminders
+
+# List every reminder of tasks in project Work:
+todoister reminders --all Work`
+)
+
+var (
+	allReminders   bool
+	reminderAt     string
+	reminderBefore string
+)
+
+// parseMinutes parses the --before flag, a number of minutes or a duration such as 1h30m,
+// exiting with an error if invalid.
+// Returns the number of minutes.
+func parseMinutes(value string) int {
+	minutes, err := strconv.Atoi(value)
+	if err != nil {
+		var d time.Duration
+		if d, err = time.ParseDuration(value); err == nil {
+			minutes = int(d.Minutes())
+		}
+	}
+	if err != nil || minutes < 0 {
+		util.Die(fmt.Sprintf("Invalid --before, expected minutes or a duration like 1h30m: %s", value), err)
+	}
+	return minutes
+}
+
+// describeReminder describes when a reminder fires, e.g., "30 minutes before it is due".
+//   - reminder: the reminder
+//   - task: the task of the reminder
+func describeReminder(reminder util.TodoistReminder, task util.TodoistItem) string {
+	switch reminder.Type {
+	case util.ReminderRelative:
+		if reminder.MinuteOffset == 0 {
+			return "when it is due"
+		}
+		return fmt.Sprintf("%d minutes before it is due", reminder.MinuteOffset)
+	case util.ReminderLocation:
+		return "at a location"
+	}
+	if at, ok := util.ReminderTime(reminder, task); ok {
+		return "on " + at.Format("Jan 2, 2006, 3:04 PM")
+	}
+	if reminder.Due != nil && reminder.Due.String != "" {
+		return fmt.Sprintf("on '%s'", reminder.Due.String)
+	}
+	return "at an unknown time"
+}
+
+var remindersCmd = &cobra.Command{
+	Use:     "reminders [flags] [NAME]",
+	Short:   "List upcoming reminders",
+	Long:    remindersLong,
+	Example: remindersExample,
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		todoistData := util.GetTodoistData(cmd.Context(), TodoistClient,
+			util.ResourceProjects, util.ResourceItems, util.ResourceReminders)
+		paths := util.ProjectPaths(todoistData.Projects)
+		prefix := ""
+		if len(args) > 0 {
+			projectID, projectPath := util.GetProjectIDByPath(args[0], todoistData)
+			if projectID == "" {
+				util.Die(fmt.Sprintf("Project '%s'", args[0]), util.ErrNotFound)
+			}
+			prefix = strings.ToLower(projectPath)
+		}
+
+		tasks := make(map[string]util.TodoistItem)
+		for _, item := range todoistData.Items {
+			if item.CompletedAt == "" {
+				tasks[item.ID] = item
+			}
+		}
+
+		type entry struct {
+			at       time.Time
+			timed    bool
+			reminder util.TodoistReminder
+			task     util.TodoistItem
+		}
+		now := time.Now()
+		var entries []entry
+		for _, reminder := range todoistData.Reminders {
+			task, ok := tasks[reminder.ItemID]
+			if !ok {
+				continue
+			}
+			lowerPath := strings.ToLower(paths[task.ProjectID])
+			if prefix != "" && lowerPath != prefix && !strings.HasPrefix(lowerPath, prefix+"/") {
+				continue
+			}
+			at, timed := util.ReminderTime(reminder, task)
+			if !allReminders && (!timed || at.Before(now)) {
+				continue
+			}
+			entries = append(entries, entry{at, timed, reminder, task})
+		}
+		// By time, then those without one
+		slices.SortStableFunc(entries, func(a, b entry) int {
+			if a.timed != b.timed {
+				if a.timed {
+					return -1
+				}
+				return 1
+			}
+			return cmp.Or(a.at.Compare(b.at), strings.Compare(a.task.Content, b.task.Content))
+		})
+
+		day := ""
+		for _, e := range entries {
+			heading := "Other"
+			if e.timed {
+				heading = e.at.Format("Mon, Jan 2, 2006")
+			}
+			if heading != day {
+				day = heading
+				fmt.Printf("\n# %s\n\n", day)
+			}
+			line := fmt.Sprintf("%s (%s)", e.task.Content, paths[e.task.ProjectID])
+			if e.reminder.Type == util.ReminderAbsolute && e.timed {
+				fmt.Printf("  %8s  %s\n", e.at.Format("3:04 PM"), line)
+			} else if e.timed {
+				fmt.Printf("  %8s  %s, %s\n", e.at.Format("3:04 PM"), line, describeReminder(e.reminder, e.task))
+			} else {
+				fmt.Printf("  %8s  %s, %s\n", "", line, describeReminder(e.reminder, e.task))
+			}
+		}
+		if len(entries) == 0 {
+			fmt.Println("No reminders")
+		}
+	},
+}
+
+func init() {
+	remindersCmd.Flags().BoolVarP(&allReminders, "all", "a", false,
+		"list past reminders too, and those without a time")
+	remindersCmd.SetHelpFunc(util.CustomHelpFunc)
+
+	RootCmd.AddCommand(remindersCmd)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/layfellow/todoister/util"
+)
+
+const remindersSyncResponse = `{
+  "sync_token": "r1",
+  "full_sync": true,
+  "projects": [
+    {"id": "6Jf8VQXxpwv56VQ7", "name": "Work", "child_order": 1}
+  ],
+  "items": [
+    {"id": "6X7rM8997g3RQmvh", "project_id": "6Jf8VQXxpwv56VQ7", "content": "Write report", "child_order": 1,
+      "due": {"date": "2099-01-16T14:00:00"}},
+    {"id": "6X7rfFVPjhvv84XG", "project_id": "6Jf8VQXxpwv56VQ7", "content": "Buy paper", "child_order": 2}
+  ],
+  "reminders": [
+    {"id": "9001", "item_id": "6X7rM8997g3RQmvh", "type": "relative", "minute_offset": 30},
+    {"id": "9002", "item_id": "6X7rfFVPjhvv84XG", "type": "absolute", "due": {"date": "2099-01-15T09:00:00"}},
+    {"id": "9003", "item_id": "6X7rfFVPjhvv84XG", "type": "absolute", "due": {"date": "2000-01-01T09:00:00"}},
+    {"id": "9004", "item_id": "6X7rfFVPjhvv84XG", "type": "location"}
+  ]
+}`
+
+func TestReminders(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var sent []util.SyncCommand
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		commands := r.FormValue("commands")
+		if commands == "" {
+			_, _ = io.WriteString(w, remindersSyncResponse)
+			return
+		}
+		var received []util.SyncCommand
+		if err := json.Unmarshal([]byte(commands), &received); err != nil {
+			t.Errorf("Failed to parse commands: %v", err)
+		}
+		sent = append(sent, received...)
+		status := make(map[string]string)
+		mapping := make(map[string]string)
+		for _, c := range received {
+			status[c.UUID] = "ok"
+			if c.TempID != "" {
+				mapping[c.TempID] = "9005"
+			}
+		}
+		_, _ = fmt.Fprintf(w, `{"sync_token":"r2","sync_status":%s,"temp_id_mapping":%s}`,
+			mustJSON(t, status), mustJSON(t, mapping))
+	}))
+	defer server.Close()
+	run := func(args ...string) string {
+		resetGlobalFlags()
+		ConfigValue.URL = server.URL + "/api/v1"
+		return runCommand(t, append([]string{"-t", "test"}, args...)...)
+	}
+
+	expected := `
+# Thu, Jan 15, 2099
+
+   9:00 AM  Buy paper (Work)
+
+# Fri, Jan 16, 2099
+
+   1:30 PM  Write report (Work), 30 minutes before it is due
+`
+	if output := run("reminders"); output != expected {
+		t.Errorf("Unexpected reminders output:\n%s", output)
+	}
+
+	expected = `
+# Sat, Jan 1, 2000
+
+   9:00 AM  Buy paper (Work)
+
+# Thu, Jan 15, 2099
+
+   9:00 AM  Buy paper (Work)
+
+# Fri, Jan 16, 2099
+
+   1:30 PM  Write report (Work), 30 minutes before it is due
+
+# Other
+
+            Buy paper (Work), at a location
+`
+	if output := run("reminders", "--all", "Work"); output != expected {
+		t.Errorf("Unexpected reminders --all output:\n%s", output)
+	}
+
+	// Reminders are added with absolute or relative triggers, and written through to the cache
+	if output := run("add", "reminder", "-p", "Work", "Write", "--before", "1h"); output != "Added reminder of task 'Write report' 60 minutes before it is due\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+	if output := run("add", "reminder", "#Work", "Buy", "--at", "2099-01-17 10:15"); output != "Added reminder of task 'Buy paper' on Jan 17, 2099, 10:15 AM\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+	if len(sent) != 2 || sent[0].Type != "reminder_add" || sent[0].Args["type"] != "relative" ||
+		sent[0].Args["minute_offset"] != float64(60) || sent[0].Args["item_id"] != "6X7rM8997g3RQmvh" {
+		t.Fatalf("Expected a relative reminder_add command, got %+v", sent)
+	}
+	if due, _ := sent[1].Args["due"].(map[string]interface{}); sent[1].Args["type"] != "absolute" || due["date"] != "2099-01-17T10:15:00" {
+		t.Errorf("Expected an absolute reminder_add command, got %+v", sent[1])
+	}
+	cached, err := util.ReadCache()
+	if err != nil || len(cached.GetReminders()) != 5 {
+		t.Errorf("Expected the new reminder in the cache, got %v (%v)", cached.GetReminders(), err)
+	}
+
+	// Reminders are chosen by their trigger when a task has more than one
+	if output := run("delete", "reminder", "-f", "-p", "Work", "Buy", "--at", "2099-01-15 09:00"); output != "Deleted reminder of task 'Buy paper' on Jan 15, 2099, 9:00 AM\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+	if len(sent) != 3 || sent[2].Type != "reminder_delete" || sent[2].Args["id"] != "9002" {
+		t.Errorf("Expected a reminder_delete command for 9002, got %+v", sent)
+	}
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const standInSyncResponse = `{
+  "sync_token": "VRyFHr0Qo3Hr--pzINyT6nax4vW7X2YG5RQlw3lB-6eYOPbSZVJepa62EVhO",
+  "full_sync": true,
+  "projects": [
+    {"id": "6Jf8VQXxpwv56VQ7", "name": "Work", "color": "blue", "parent_id": null, "child_order": 1}
+  ],
+  "sections": [],
+  "items": [
+    {"id": "6X7rM8997g3RQmvh", "project_id": "6Jf8VQXxpwv56VQ7", "content": "Write report", "priority": 1, "child_order": 1},
+    {"id": "6X7rfFVPjhvv84XG", "project_id": "6Jf8VQXxpwv56VQ7", "content": "Buy paper", "priority": 1, "child_order": 2}
+  ],
+  "labels": [],
+  "notes": [],
+  "project_notes": []
+}`
+
+// newStandInServer returns a local stand-in for the Sync API that serves
+// standInSyncResponse and accepts every command.
+func newStandInServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		commands := r.FormValue("commands")
+		if commands == "" {
+			_, _ = io.WriteString(w, standInSyncResponse)
+			return
+		}
+		var received []struct {
+			UUID string `json:"uuid"`
+		}
+		if err := json.Unmarshal([]byte(commands), &received); err != nil {
+			t.Errorf("Failed to parse commands: %v", err)
+		}
+		status := make(map[string]string)
+		for _, c := range received {
+			status[c.UUID] = "ok"
+		}
+		_, _ = fmt.Fprintf(w, `{"sync_token":"abc","sync_status":%s,"temp_id_mapping":{}}`, mustJSON(t, status))
+	}))
+}
+
+// runCommand executes the root command with args and returns its standard output.
+func runCommand(t *testing.T, args ...string) string {
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	RootCmd.SetArgs(args)
+	err := RootCmd.Execute()
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+
+	if err != nil {
+		t.Fatalf("Failed to execute %v: %v", args, err)
+	}
+	return buf.String()
+}
+
+// resetGlobalFlags clears the flags that persist between executions of RootCmd.
+func resetGlobalFlags() {
+	ConfigValue.RecordDir = ""
+	ConfigValue.ReplayDir = ""
+	ConfigValue.URL = ""
+	ConfigValue.Offline = false
+	ConfigValue.MaxAge = 0
+	ConfigValue.NoSync = false
+	ConfigValue.Sync = false
+	ConfigValue.Passphrase = ""
+	ConfigValue.KeyFile = ""
+	ConfigValue.KeyCommand = ""
+	checkProjectFlag = ""
+	deleteProjectFlag = ""
+	forceDelete = false
+	projectFlag = ""
+	dateFlag = ""
+	assigneeFlag = ""
+	allReminders = false
+	reminderAt = ""
+	reminderBefore = ""
+	dropAll = false
+	dumpJSON = false
+	sinceFlag = ""
+	untilFlag = ""
+	includeCompleted = false
+	changesSince = 24 * time.Hour
+	changesProjectFlag = ""
+	for _, name := range []string{"record", "replay", "offline", "max-age", "no-sync", "sync"} {
+		RootCmd.PersistentFlags().Lookup(name).Changed = false
+	}
+}
+
+func TestReplayCheck(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+
+	output := runCommand(t, "--replay", "testdata/replay/check", "-t", "test", "check", "-p", "Work", "Write")
+
+	if output != "✓ Completed: Write report\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+}
+
+func TestReplayDeleteTask(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+
+	output := runCommand(t, "--replay", "testdata/replay/delete", "-t", "test", "delete", "task", "-f", "-p", "Work", "Buy")
+
+	if output != "Deleted task 'Buy paper'\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+}
+
+func TestRecordRedactsToken(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	server := newStandInServer(t)
+	defer server.Close()
+
+	const token = "0123456789abcdef0123456789abcdef01234567"
+	dir := filepath.Join(t.TempDir(), "fixtures")
+	ConfigValue.URL = server.URL + "/api/v1"
+	runCommand(t, "--record", dir, "-t", token, "check", "-p", "Work", "Write")
+
+	for _, name := range []string{"0001.json", "0002.json"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected fixture %s: %v", name, err)
+		}
+		if strings.Contains(string(data), token) {
+			t.Errorf("Fixture %s contains the API token", name)
+		}
+		if !strings.Contains(string(data), "Bearer REDACTED") {
+			t.Errorf("Fixture %s lacks the redacted Authorization header", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "0003.json")); err == nil {
+		t.Error("Expected exactly two fixtures")
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/layfellow/todoister/util"
+)
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     []int // Status codes returned before succeeding
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "no failures", failures: nil, wantAttempts: 1},
+		{name: "transient 502", failures: []int{502, 503}, wantAttempts: 3},
+		{name: "rate limited", failures: []int{429}, wantAttempts: 2},
+		{name: "gives up after max attempts", failures: []int{500, 500, 500}, wantAttempts: 3, wantErr: true},
+		{name: "client error not retried", failures: []int{400}, wantAttempts: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			var requestIDs []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestIDs = append(requestIDs, r.Header.Get("X-Request-Id"))
+				attempts++
+				if attempts <= len(tt.failures) {
+					if tt.failures[attempts-1] == http.StatusTooManyRequests {
+						w.Header().Set("Retry-After", "0")
+					}
+					w.WriteHeader(tt.failures[attempts-1])
+					return
+				}
+				_, _ = w.Write([]byte(`{"id":"1","name":"Shopping"}`))
+			}))
+			defer server.Close()
+
+			config := util.ConfigType{Token: "secret"}
+			config.URL = server.URL
+			config.MaxAttempts = 3
+			config.InitialBackoff = time.Millisecond
+			config.MaxBackoff = 10 * time.Millisecond
+			client := util.NewClient(&config, "DEV")
+
+			_, err := client.CreateProject(context.Background(), "Shopping", "", "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+			// Retries must reuse the same X-Request-Id so Todoist can discard duplicates
+			for _, id := range requestIDs {
+				if id == "" || id != requestIDs[0] {
+					t.Errorf("Expected the same X-Request-Id on every attempt, got %v", requestIDs)
+					break
+				}
+			}
+		})
+	}
+}
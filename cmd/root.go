@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/layfellow/todoister/util"
 	"github.com/spf13/cobra"
 )
@@ -9,6 +14,20 @@ const (
 	VersionText = "Minimal Todoist CLI client"
 
 	rootLong = `Todoister is a simple Todoist CLI client written in Go.
+
+Exit codes:
+
+- <code>0</code> success
+- <code>1</code> general failure
+- <code>2</code> invalid command line
+- <code>3</code> authentication failed (missing, invalid or expired token)
+- <code>4</code> project or task not found
+- <code>5</code> ambiguous match, more than one task matches
+- <code>6</code> rate limited by Todoist
+- <code>7</code> network unavailable
+- <code>8</code> timed out, see <code>--timeout</code>
+- <code>9</code> another todoister process kept the cache locked
+- <code>130</code> interrupted with Ctrl-C
 `
 )
 
@@ -16,28 +35,84 @@ var Version = "DEV"
 
 var ConfigValue util.ConfigType
 
+// TodoistClient is the API client shared by all commands.
+var TodoistClient *util.Client
+
 var RootCmd = &cobra.Command{
 	Use:               "todoister command [arguments]",
 	Version:           Version,
 	Short:             VersionText,
 	Long:              rootLong,
 	DisableAutoGenTag: true,
+	PersistentPreRun:  applyTimeout,
+}
+
+// cancelTimeout releases the timer set by the --timeout flag.
+var cancelTimeout context.CancelFunc = func() {}
+
+// applyTimeout bounds the command's context by the --timeout flag or config default.
+func applyTimeout(cmd *cobra.Command, args []string) {
+	if ConfigValue.Timeout > 0 {
+		var ctx context.Context
+		ctx, cancelTimeout = context.WithTimeout(cmd.Context(), ConfigValue.Timeout)
+		cmd.SetContext(ctx)
+	}
 }
 
 func initAll() {
-	util.SchemaVersion = Version
 	util.InitConfig(&ConfigValue)
-	util.InitLogger(ConfigValue.Name)
+	util.InitLogger(ConfigValue.Name, ConfigValue.Debug)
+	util.SelectCacheAccount(&ConfigValue)
+	if err := util.SetCacheEncryption(&ConfigValue); err != nil {
+		util.Die("Invalid cache encryption settings", err)
+	}
+	TodoistClient = util.NewClient(&ConfigValue, Version)
 }
 
 func init() {
 	cobra.OnInitialize(initAll)
 	RootCmd.PersistentFlags().StringVarP(&ConfigValue.Token, "token", "t", "",
 		"use <string> as Todoist API token")
+	RootCmd.PersistentFlags().DurationVar(&ConfigValue.Timeout, "timeout", 0,
+		"abort the command if it takes longer than <duration>, e.g., 30s or 2m\n(default is no limit)")
+	RootCmd.PersistentFlags().BoolVar(&ConfigValue.Debug, "debug", false,
+		"log every API call to stderr and the log file, with the token redacted")
+	RootCmd.PersistentFlags().StringVar(&ConfigValue.RecordDir, "record", "",
+		"save every API request and response in directory <string>,\nwith the token redacted")
+	RootCmd.PersistentFlags().StringVar(&ConfigValue.ReplayDir, "replay", "",
+		"answer API requests from the responses saved by --record in directory\n<string>, without network access or touching the local cache")
+	RootCmd.PersistentFlags().BoolVar(&ConfigValue.Offline, "offline", false,
+		"work from the local cache without network access, queuing changes\nfor the next sync, see the queue command")
+	RootCmd.PersistentFlags().DurationVar(&ConfigValue.MaxAge, "max-age", 0,
+		"use the local cache without syncing if it was synced less than <duration> ago,\ne.g., 5m (default is cache.max_age, or always sync)")
+	RootCmd.PersistentFlags().BoolVar(&ConfigValue.NoSync, "no-sync", false,
+		"use the local cache without syncing, however old it is")
+	RootCmd.PersistentFlags().BoolVar(&ConfigValue.Sync, "sync", false,
+		"always sync, ignoring cache.max_age")
+	RootCmd.MarkFlagsMutuallyExclusive("record", "replay")
+	RootCmd.MarkFlagsMutuallyExclusive("sync", "no-sync")
+	RootCmd.MarkFlagsMutuallyExclusive("sync", "max-age")
+	RootCmd.MarkFlagsMutuallyExclusive("sync", "offline")
+	RootCmd.MarkFlagsMutuallyExclusive("offline", "record")
+	RootCmd.MarkFlagsMutuallyExclusive("offline", "replay")
 	RootCmd.SetVersionTemplate(`{{printf "` + VersionText + ` v%s\n" .Version }}`)
 	RootCmd.SetHelpFunc(util.CustomHelpFunc)
 }
 
 func Execute() {
-	_ = RootCmd.Execute()
+	// Ctrl-C cancels the command's context so in-flight requests stop cleanly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// Restore the default behavior so a second Ctrl-C terminates at once.
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := RootCmd.ExecuteContext(ctx)
+	cancelTimeout()
+	stop()
+	if err != nil {
+		// Cobra has already printed the error and usage
+		os.Exit(util.ExitUsage)
+	}
 }
@@ -0,0 +1,500 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layfellow/todoister/util"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPerResourceSyncTokens(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	type exchange struct {
+		resourceTypes string
+		syncToken     string
+		response      string
+	}
+	// Expected requests, in order, and the stand-in responses
+	exchanges := []exchange{
+		// 1. Projects only, never synced, with the user the first time
+		{`["projects","user"]`, "*", `{"sync_token":"t1","full_sync":true,"projects":[
+			{"id":"1","name":"Work"},{"id":"2","name":"Home"}],"user":{"id":"42"}}`},
+		// 2. Projects incrementally, items for the first time
+		{`["projects"]`, "t1", `{"sync_token":"t2","full_sync":false,"projects":[]}`},
+		{`["items"]`, "*", `{"sync_token":"t3","full_sync":true,"items":[
+			{"id":"10","project_id":"1","content":"Write report"},
+			{"id":"20","project_id":"2","content":"Fix sink"}]}`},
+		// 3. Projects only, Home was deleted
+		{`["projects"]`, "t2", `{"sync_token":"t4","full_sync":false,"projects":[
+			{"id":"2","name":"Home","is_deleted":true}]}`},
+		// 4. Items with their own token
+		{`["items"]`, "t3", `{"sync_token":"t5","full_sync":false,"items":[]}`},
+	}
+
+	n := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n >= len(exchanges) {
+			t.Errorf("Unexpected request %d", n+1)
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		e := exchanges[n]
+		n++
+		if got := r.FormValue("resource_types"); got != e.resourceTypes {
+			t.Errorf("Request %d: expected resource_types %s, got %s", n, e.resourceTypes, got)
+		}
+		if got := r.FormValue("sync_token"); got != e.syncToken {
+			t.Errorf("Request %d: expected sync_token %s, got %s", n, e.syncToken, got)
+		}
+		_, _ = io.WriteString(w, e.response)
+	}))
+	defer server.Close()
+
+	config := util.ConfigType{Token: "secret"}
+	config.URL = server.URL
+	client := util.NewClient(&config, "DEV")
+	ctx := context.Background()
+
+	data := util.GetTodoistData(ctx, client, util.ResourceProjects)
+	if len(data.Projects) != 2 || len(data.Items) != 0 {
+		t.Errorf("Expected 2 projects and no items, got %d and %d", len(data.Projects), len(data.Items))
+	}
+
+	data = util.GetTodoistData(ctx, client, util.ResourceProjects, util.ResourceItems)
+	if len(data.Projects) != 2 || len(data.Items) != 2 {
+		t.Errorf("Expected 2 projects and 2 items, got %d and %d", len(data.Projects), len(data.Items))
+	}
+
+	// Deleting a project removes its cached items, even if items were not synced
+	data = util.GetTodoistData(ctx, client, util.ResourceProjects)
+	if len(data.Projects) != 1 || len(data.Items) != 1 || data.Items[0].ID != "10" {
+		t.Errorf("Expected 1 project and item 10, got %+v and %+v", data.Projects, data.Items)
+	}
+
+	data = util.GetTodoistData(ctx, client, util.ResourceItems)
+	if len(data.Items) != 1 {
+		t.Errorf("Expected 1 item, got %d", len(data.Items))
+	}
+
+	if n != len(exchanges) {
+		t.Errorf("Expected %d requests, got %d", len(exchanges), n)
+	}
+}
+
+func TestSubtaskDeletionCascades(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	responses := []string{
+		`{"sync_token":"t1","full_sync":true,"items":[
+			{"id":"10","project_id":"1","content":"Plan trip"},
+			{"id":"11","project_id":"1","parent_id":"10","content":"Book flights"},
+			{"id":"12","project_id":"1","parent_id":"11","content":"Compare fares"},
+			{"id":"20","project_id":"1","content":"Water plants"}]}`,
+		`{"sync_token":"t2","full_sync":false,"items":[
+			{"id":"10","project_id":"1","content":"Plan trip","is_deleted":true}]}`,
+	}
+	n := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n >= len(responses) {
+			t.Errorf("Unexpected request %d", n+1)
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		_, _ = io.WriteString(w, responses[n])
+		n++
+	}))
+	defer server.Close()
+
+	config := util.ConfigType{Token: "secret"}
+	config.URL = server.URL
+	client := util.NewClient(&config, "DEV")
+	ctx := context.Background()
+
+	data := util.GetTodoistData(ctx, client, util.ResourceItems)
+	if len(data.Items) != 4 || data.Items[2].ParentID != "11" {
+		t.Fatalf("Expected 4 items with parents, got %+v", data.Items)
+	}
+
+	// The cached parent IDs are needed to cascade the deletion
+	data = util.GetTodoistData(ctx, client, util.ResourceItems)
+	if len(data.Items) != 1 || data.Items[0].ID != "20" {
+		t.Errorf("Expected only item 20 after deleting its sibling tree, got %+v", data.Items)
+	}
+}
+
+func TestMetadataSurvivesCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	responses := []string{
+		`{"sync_token":"t1","full_sync":true,
+		"projects":[{"id":"1","name":"Inbox","inbox_project":true,"shared":true,"is_favorite":true,
+			"created_at":"2023-07-13T10:20:59Z","updated_at":"2024-12-10T13:27:29Z"}],
+		"items":[{"id":"10","project_id":"1","content":"File taxes","added_at":"2025-01-21T21:28:43Z",
+			"updated_at":"2025-01-22T08:00:00Z","deadline":{"date":"2025-04-15","lang":"en"},
+			"responsible_uid":"2671355","assigned_by_uid":"2671362","note_count":1}],
+		"notes":[{"id":"100","item_id":"10","task_id":"10","content":"Ask the accountant",
+			"posted_at":"2025-01-23T09:00:00Z","posted_uid":"2671362"}]}`,
+		`{"sync_token":"t2","full_sync":false,"projects":[],"items":[],"notes":[]}`,
+	}
+	n := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n >= len(responses) {
+			t.Errorf("Unexpected request %d", n+1)
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		_, _ = io.WriteString(w, responses[n])
+		n++
+	}))
+	defer server.Close()
+
+	config := util.ConfigType{Token: "secret"}
+	config.URL = server.URL
+	client := util.NewClient(&config, "DEV")
+	resources := []string{util.ResourceProjects, util.ResourceItems, util.ResourceNotes}
+
+	_ = util.GetTodoistData(context.Background(), client, resources...)
+	// The second sync changes nothing, so everything comes from the cache
+	data := util.GetTodoistData(context.Background(), client, resources...)
+
+	roots := util.HierarchicalData(data)
+	if len(roots) != 1 || len(roots[0].Tasks) != 1 || len(roots[0].Tasks[0].Comments) != 1 {
+		t.Fatalf("Expected a project with a commented task, got %+v", data)
+	}
+	project, task, comment := roots[0], roots[0].Tasks[0], roots[0].Tasks[0].Comments[0]
+	expected := util.Project{Name: "Inbox", CreatedAt: "2023-07-13T10:20:59Z", UpdatedAt: "2024-12-10T13:27:29Z",
+		IsFavorite: true, IsShared: true, InboxProject: true}
+	if project.Project != expected {
+		t.Errorf("Expected project %+v, got %+v", expected, project.Project)
+	}
+	if task.AddedAt != "2025-01-21T21:28:43Z" || task.UpdatedAt != "2025-01-22T08:00:00Z" ||
+		task.ResponsibleUID != "2671355" || task.AssignedByUID != "2671362" || task.NoteCount != 1 ||
+		task.Deadline == nil || task.Deadline.Date != "2025-04-15" {
+		t.Errorf("Task metadata lost in the cache: %+v, deadline %+v", task.Task, task.Deadline)
+	}
+	if comment.PostedAt != "2025-01-23T09:00:00Z" || comment.PostedUID != "2671362" {
+		t.Errorf("Comment metadata lost in the cache: %+v", comment.Comment)
+	}
+}
+
+func TestCacheLock(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	ctx := context.Background()
+
+	lock, err := util.LockCache(ctx, time.Second)
+	if err != nil {
+		t.Fatalf("LockCache failed: %v", err)
+	}
+
+	// A second holder, as another process would be, waits and gives up
+	start := time.Now()
+	_, err = util.LockCache(ctx, 200*time.Millisecond)
+	if util.ExitCode(err) != util.ExitLocked {
+		t.Errorf("Expected exit code %d for a held lock, got %d (%v)", util.ExitLocked, util.ExitCode(err), err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Expected to wait about 200ms for the lock, waited %s", elapsed)
+	}
+	if err != nil && !strings.Contains(err.Error(), fmt.Sprintf("pid %d", os.Getpid())) {
+		t.Errorf("Expected the error to name the holder, got %v", err)
+	}
+
+	lock.Unlock()
+	lock, err = util.LockCache(ctx, time.Second)
+	if err != nil {
+		t.Fatalf("LockCache after Unlock failed: %v", err)
+	}
+	lock.Unlock()
+}
+
+func TestSaveCacheIsAtomic(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if err := util.SaveCache(&util.CachedTodoistData{SyncTokens: map[string]string{"items": "t1"}}); err != nil {
+		t.Fatalf("SaveCache failed: %v", err)
+	}
+	cachePath, _ := util.GetCachePath()
+	entries, err := os.ReadDir(filepath.Dir(cachePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			t.Errorf("Temporary file left behind: %s", entry.Name())
+		}
+	}
+	cached, err := util.LoadCache()
+	if err != nil || cached == nil || cached.GetSyncTokens()["items"] != "t1" {
+		t.Errorf("Expected the saved cache back, got %v (%v)", cached, err)
+	}
+}
+
+func TestWriteThroughCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		commands := r.FormValue("commands")
+		if commands == "" {
+			_, _ = io.WriteString(w, standInSyncResponse)
+			return
+		}
+		var received []struct {
+			UUID   string `json:"uuid"`
+			TempID string `json:"temp_id"`
+		}
+		if err := json.Unmarshal([]byte(commands), &received); err != nil {
+			t.Errorf("Failed to parse commands: %v", err)
+		}
+		status, tempIDs := make(map[string]string), make(map[string]string)
+		for _, c := range received {
+			status[c.UUID] = "ok"
+			if c.TempID != "" {
+				tempIDs[c.TempID] = "6X7rNewTask"
+			}
+		}
+		_, _ = fmt.Fprintf(w, `{"sync_token":"abc","sync_status":%s,"temp_id_mapping":%s}`,
+			mustJSON(t, status), mustJSON(t, tempIDs))
+	}))
+	defer server.Close()
+
+	config := util.ConfigType{Token: "secret"}
+	config.URL = server.URL
+	client := util.NewClient(&config, "DEV")
+	ctx := context.Background()
+	_ = util.GetTodoistData(ctx, client, util.ResourceProjects, util.ResourceItems)
+	before, err := util.LoadCache()
+	if err != nil || before == nil {
+		t.Fatalf("Expected a cache: %v", err)
+	}
+
+	batch := util.NewCommandBatch()
+	batch.ItemClose("6X7rM8997g3RQmvh")
+	batch.ItemAdd(util.TaskAddArgs("Call the printer", "6Jf8VQXxpwv56VQ7", nil, ""))
+	if _, err := client.CommitOrQueue(ctx, batch, "test"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	cached, err := util.LoadCache()
+	if err != nil || cached == nil {
+		t.Fatalf("Expected a cache: %v", err)
+	}
+	items := make(map[string]*util.PbItem)
+	for _, item := range cached.Items {
+		items[item.Id] = item
+	}
+	if items["6X7rM8997g3RQmvh"].GetCompletedAt() == "" {
+		t.Error("Expected the completed task to be completed in the cache")
+	}
+	if items["6X7rNewTask"].GetContent() != "Call the printer" {
+		t.Errorf("Expected the new task in the cache under its real ID, got %v", cached.Items)
+	}
+	if cached.CachedAt != before.CachedAt || cached.SyncTokens[util.ResourceItems] != before.SyncTokens[util.ResourceItems] {
+		t.Error("Expected writing through to keep the sync state of the cache")
+	}
+
+	batch = util.NewCommandBatch()
+	batch.ProjectDelete("6Jf8VQXxpwv56VQ7")
+	if _, err := client.CommitOrQueue(ctx, batch, "test"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if cached, _ = util.LoadCache(); len(cached.Projects) != 0 {
+		t.Errorf("Expected the deleted project gone from the cache, got %v", cached.Projects)
+	}
+}
+
+func TestCacheMaxAge(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = io.WriteString(w, standInSyncResponse)
+	}))
+	defer server.Close()
+
+	config := util.ConfigType{Token: "secret"}
+	config.URL = server.URL
+	config.MaxAge = time.Hour
+	client := util.NewClient(&config, "DEV")
+	ctx := context.Background()
+
+	steps := []struct {
+		name      string
+		setup     func()
+		resources []string
+		requests  int
+	}{
+		{"no cache", func() {}, []string{util.ResourceProjects}, 1},
+		{"fresh cache", func() {}, []string{util.ResourceProjects}, 1},
+		{"items never synced", func() {}, []string{util.ResourceProjects, util.ResourceItems}, 3},
+		{"fresh cache with items", func() {}, []string{util.ResourceProjects, util.ResourceItems}, 3},
+		{"always sync", func() { client.MaxAge = 0 }, []string{util.ResourceItems}, 4},
+		{"no sync", func() { client.NoSync = true }, []string{util.ResourceItems}, 4},
+		{"stale cache", func() { client.NoSync, client.MaxAge = false, time.Nanosecond }, []string{util.ResourceItems}, 5},
+	}
+	for _, step := range steps {
+		step.setup()
+		data := util.GetTodoistData(ctx, client, step.resources...)
+		if requests != step.requests {
+			t.Errorf("%s: expected %d requests so far, got %d", step.name, step.requests, requests)
+		}
+		if len(data.Projects) != 1 {
+			t.Errorf("%s: expected the cached project, got %+v", step.name, data.Projects)
+		}
+	}
+}
+
+func TestCacheMigrations(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	var syncToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		syncToken = r.FormValue("sync_token")
+		_, _ = io.WriteString(w, `{"sync_token":"t2","full_sync":false,"items":[]}`)
+	}))
+	defer server.Close()
+
+	config := util.ConfigType{Token: "secret"}
+	config.URL = server.URL
+	client := util.NewClient(&config, "DEV")
+
+	// A cache as release 0.4 saved it, shared by all accounts, with a single sync token
+	// and its schema in a version file
+	legacyDir := filepath.Join(cacheHome, "todoister")
+	legacy, _ := proto.Marshal(&util.CachedTodoistData{
+		SyncToken: "t1",
+		CachedAt:  time.Now().Unix(),
+		Projects:  []*util.PbProject{{Id: "1", Name: "Work"}},
+		Items:     []*util.PbItem{{Id: "10", ProjectId: "1", Content: "Write report"}},
+	})
+	saveLegacy := func(version string) {
+		if err := os.RemoveAll(legacyDir); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(legacyDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(legacyDir, "todoist.pb"), legacy, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if version != "" {
+			if err := os.WriteFile(filepath.Join(legacyDir, "version"), []byte(version), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		util.SetCacheAccount("secret")
+	}
+
+	// The 0.4 cache is moved to the account's directory and kept, but synced in full
+	saveLegacy("schema=0.4\n")
+	cached, err := util.LoadCache()
+	if err != nil || cached == nil {
+		t.Fatalf("Expected the 0.4 cache upgraded, got %v", err)
+	}
+	if len(cached.Projects) != 1 || len(cached.Items) != 1 || cached.Items[0].Content != "Write report" {
+		t.Errorf("Expected the cached data kept, got %v", cached)
+	}
+	util.GetTodoistData(context.Background(), client, util.ResourceItems)
+	if syncToken != "*" {
+		t.Errorf("Expected a full sync of the 0.4 cache, got sync token %s", syncToken)
+	}
+	cached, err = util.ReadCache()
+	if err != nil || cached.GetSchema() != util.CacheSchema || cached.GetAccount() != util.CacheAccount() ||
+		cached.GetSyncToken() != "" || len(cached.Projects) != 1 {
+		t.Errorf("Expected the cache saved for the account with schema %d, got %v (%v)", util.CacheSchema, cached, err)
+	}
+	versionPath, _ := util.GetVersionFilePath()
+	if _, err := os.Stat(versionPath); !os.IsNotExist(err) {
+		t.Error("Expected the version file removed")
+	}
+
+	// Saved by this version, the cache is synced incrementally
+	util.GetTodoistData(context.Background(), client, util.ResourceItems)
+	if syncToken != "t2" {
+		t.Errorf("Expected an incremental sync, got sync token %s", syncToken)
+	}
+
+	// Caches of earlier releases, of unknown versions, of later versions or of other accounts are dropped
+	saveLegacy("schema=0.3\n")
+	if cached, _ := util.LoadCache(); cached != nil {
+		t.Errorf("Expected the 0.3 cache dropped, got %v", cached)
+	}
+	saveLegacy("")
+	if cached, _ := util.LoadCache(); cached != nil {
+		t.Errorf("Expected the cache of an unknown version dropped, got %v", cached)
+	}
+	cachePath, _ := util.GetCachePath()
+	for _, c := range []*util.CachedTodoistData{
+		{Account: util.CacheAccount(), Schema: util.CacheSchema + 1},
+		{Account: util.AccountKey("other"), Schema: util.CacheSchema},
+	} {
+		data, _ := proto.Marshal(c)
+		if err := os.WriteFile(cachePath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if cached, _ := util.LoadCache(); cached != nil {
+			t.Errorf("Expected the cache dropped, got %v", cached)
+		}
+	}
+}
+
+func TestCloseRecurringTask(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	response := strings.Replace(standInSyncResponse, `"content": "Buy paper", "priority": 1,`,
+		`"content": "Buy paper", "priority": 1, "due": {"date": "2099-01-16", "is_recurring": true, "string": "every day"},`, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		commands := r.FormValue("commands")
+		if commands == "" {
+			_, _ = io.WriteString(w, response)
+			return
+		}
+		var received []util.SyncCommand
+		if err := json.Unmarshal([]byte(commands), &received); err != nil {
+			t.Errorf("Failed to parse commands: %v", err)
+		}
+		status := make(map[string]string)
+		for _, c := range received {
+			status[c.UUID] = "ok"
+		}
+		_, _ = fmt.Fprintf(w, `{"sync_token":"abc","sync_status":%s,"temp_id_mapping":{}}`, mustJSON(t, status))
+	}))
+	defer server.Close()
+	run := func(args ...string) string {
+		resetGlobalFlags()
+		ConfigValue.URL = server.URL + "/api/v1"
+		return runCommand(t, append([]string{"-t", "test"}, args...)...)
+	}
+
+	// Checking a recurring task moves it to its next date, so it stays incomplete
+	// in the cache, and with the change queued offline
+	run("check", "-p", "Work", "Buy")
+	cached, err := util.ReadCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range cached.Items {
+		if item.Id == "6X7rfFVPjhvv84XG" && item.GetCompletedAt() != "" {
+			t.Errorf("Expected the recurring task incomplete in the cache, got %v", item)
+		}
+	}
+	for range 2 {
+		if output := run("--offline", "check", "-p", "Work", "Buy"); output != "Queued for the next sync: complete task 'Buy paper'\n" {
+			t.Errorf("Expected the recurring task checked offline, got %q", output)
+		}
+	}
+
+}
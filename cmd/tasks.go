@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/layfellow/todoister/util"
@@ -13,7 +14,8 @@ const (
 
 <code>NAME</code> is the name of one or more projects to list tasks from.
 You can specify a project name by its full path, e.g., <code>Work/Project</code>.
-Names are case-insensitive.
+Names are case-insensitive. Subtasks are indented below their parent task.
+Tasks of shared projects show the name of their assignee in brackets.
 `
 
 	tasksExample = `# List tasks for project Life:
@@ -26,8 +28,17 @@ todoister tasks Work/Project
 todoister tasks Life Work/Project`
 )
 
-func printTasks(tasks []*util.ExportedTask) {
+// printTasks prints tasks with their due dates, assignees and descriptions, and their
+// subtasks below them.
+//   - tasks: the tasks to print
+//   - depth: the nesting level of the tasks, 0 for top-level tasks
+func printTasks(tasks []*util.ExportedTask, depth int) {
+	indent := strings.Repeat("  ", depth+1)
 	for _, task := range tasks {
+		assignee := ""
+		if task.Assignee != "" {
+			assignee = fmt.Sprintf(" [%s]", task.Assignee)
+		}
 		if task.Due != nil && task.Due.Datetime != "" {
 			// Task has a specific datetime in the Datetime field
 			dueStr := task.Due.Datetime
@@ -37,7 +48,7 @@ func printTasks(tasks []*util.ExportedTask) {
 			} else if t, err := time.Parse("2006-01-02T15:04:05", task.Due.Datetime); err == nil {
 				dueStr = t.Format("Jan 2, 2006, 3:04 PM")
 			}
-			fmt.Printf("  - %s (%s)\n", task.Content, dueStr)
+			fmt.Printf("%s- %s (%s)%s\n", indent, task.Content, dueStr, assignee)
 		} else if task.Due != nil && task.Due.Date != "" {
 			// Task has a Date field - may contain date-only or datetime
 			dueStr := task.Due.Date
@@ -49,13 +60,14 @@ func printTasks(tasks []*util.ExportedTask) {
 			} else if t, err := time.Parse("2006-01-02", task.Due.Date); err == nil {
 				dueStr = t.Format("Jan 2, 2006")
 			}
-			fmt.Printf("  - %s (%s)\n", task.Content, dueStr)
+			fmt.Printf("%s- %s (%s)%s\n", indent, task.Content, dueStr, assignee)
 		} else {
-			fmt.Printf("  - %s\n", task.Content)
+			fmt.Printf("%s- %s%s\n", indent, task.Content, assignee)
 		}
 		if task.Description != "" {
-			fmt.Printf("%s\n\n", util.IndentMultilineString(task.Description, 4))
+			fmt.Printf("%s\n\n", util.IndentMultilineString(task.Description, len(indent)+2))
 		}
+		printTasks(task.Subtasks, depth+1)
 	}
 }
 
@@ -67,18 +79,20 @@ var tasksCmd = &cobra.Command{
 	Example: tasksExample,
 	Args:    cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		projectData := util.HierarchicalData(util.GetTodoistData(ConfigValue.Token))
+		projectData := util.HierarchicalData(util.GetTodoistData(cmd.Context(), TodoistClient,
+			util.ResourceProjects, util.ResourceSections, util.ResourceItems,
+			util.ResourceUser, util.ResourceCollaborators))
 		project := util.ExportedProject{Subprojects: projectData}
 		project.Name = "Projects"
 
 		for _, arg := range args {
 			if actualPathname, p := util.GetProjectByPathName(arg, &project); p != nil && p.Tasks != nil {
 				fmt.Printf("\n# %s\n\n", actualPathname)
-				printTasks(p.Tasks)
+				printTasks(p.Tasks, 0)
 				if p.Sections != nil {
 					for _, s := range p.Sections {
 						fmt.Printf("\n  /%s\n\n", s.Name)
-						printTasks(s.Tasks)
+						printTasks(s.Tasks, 0)
 					}
 				}
 			}
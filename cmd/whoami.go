@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/layfellow/todoister/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	whoamiLong = `Show the Todoist account in use: its name, email, timezone and plan.
+
+The account is synced and cached like the rest of your data, so this also works offline.
+`
+
+	whoamiExample = `# Show the account of the configured token:
+todoister whoami
+
+# Show the account of another token:
+todoister --token='another-todoist-API-token' whoami`
+)
+
+var whoamiCmd = &cobra.Command{
+	Use:     "whoami",
+	Short:   "Show the Todoist account in use",
+	Long:    whoamiLong,
+	Example: whoamiExample,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		user := util.GetTodoistData(cmd.Context(), TodoistClient, util.ResourceUser).User
+		if user == nil {
+			util.Die("No account in the cache, run a command online first", util.ErrNetwork)
+		}
+
+		fmt.Printf("Account:  %s <%s>\n", user.FullName, user.Email)
+		fmt.Printf("ID:       %s\n", user.ID)
+		if tz := user.TzInfo; tz.GMTString != "" {
+			fmt.Printf("Timezone: %s (GMT%s)\n", tz.Timezone, tz.GMTString)
+		} else {
+			fmt.Printf("Timezone: %s\n", tz.Timezone)
+		}
+		fmt.Printf("Plan:     %s\n", user.Plan())
+	},
+}
+
+func init() {
+	whoamiCmd.SetHelpFunc(util.CustomHelpFunc)
+	RootCmd.AddCommand(whoamiCmd)
+}
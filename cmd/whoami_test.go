@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/layfellow/todoister/util"
+)
+
+const collaboratorsSyncResponse = `{
+  "sync_token": "c1",
+  "full_sync": true,
+  "projects": [
+    {"id": "6Jf8VQXxpwv56VQ7", "name": "Work", "child_order": 1},
+    {"id": "6Jf8VQXxpwv56VQ8", "name": "Team", "child_order": 2, "shared": true}
+  ],
+  "items": [
+    {"id": "6X7rM8997g3RQmvh", "project_id": "6Jf8VQXxpwv56VQ8", "content": "Review budget", "child_order": 1, "responsible_uid": "102"},
+    {"id": "6X7rfFVPjhvv84XG", "project_id": "6Jf8VQXxpwv56VQ8", "content": "Book room", "child_order": 2, "responsible_uid": "101"},
+    {"id": "6X7rfFVPjhvv84XH", "project_id": "6Jf8VQXxpwv56VQ8", "content": "Order cake", "child_order": 3}
+  ],
+  "user": {"id": "101", "email": "me@example.com", "full_name": "Marco Bravo",
+    "tz_info": {"timezone": "Europe/Madrid", "gmt_string": "+02:00"}, "premium_status": "current_personal_plan"},
+  "collaborators": [
+    {"id": "101", "email": "me@example.com", "full_name": "Marco Bravo"},
+    {"id": "102", "email": "alice@example.com", "full_name": "Alice Smith"},
+    {"id": "103", "email": "alice.s@example.com", "full_name": "Alice Smith"},
+    {"id": "104", "email": "bob@example.com", "full_name": "Bob Jones"}
+  ],
+  "collaborator_states": [
+    {"project_id": "6Jf8VQXxpwv56VQ8", "user_id": "101", "state": "active"},
+    {"project_id": "6Jf8VQXxpwv56VQ8", "user_id": "102", "state": "active"},
+    {"project_id": "6Jf8VQXxpwv56VQ8", "user_id": "103", "state": "active"},
+    {"project_id": "6Jf8VQXxpwv56VQ8", "user_id": "104", "state": "deleted"}
+  ]
+}`
+
+func TestCollaborators(t *testing.T) {
+	t.Cleanup(resetGlobalFlags)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var assignees []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if commands := r.FormValue("commands"); commands != "" {
+			var received []util.SyncCommand
+			if err := json.Unmarshal([]byte(commands), &received); err != nil {
+				t.Errorf("Failed to parse commands: %v", err)
+			}
+			for _, c := range received {
+				assignee, _ := c.Args["responsible_uid"].(string)
+				assignees = append(assignees, assignee)
+				_, _ = fmt.Fprintf(w, `{"sync_token":"c2","sync_status":{%q:"ok"},"temp_id_mapping":{%q:"6X7rfFVPjhvv84XX"}}`,
+					c.UUID, c.TempID)
+			}
+			return
+		}
+		_, _ = io.WriteString(w, collaboratorsSyncResponse)
+	}))
+	defer server.Close()
+	run := func(args ...string) string {
+		resetGlobalFlags()
+		ConfigValue.URL = server.URL + "/api/v1"
+		return runCommand(t, append([]string{"-t", "test"}, args...)...)
+	}
+
+	expected := `Account:  Marco Bravo <me@example.com>
+ID:       101
+Timezone: Europe/Madrid (GMT+02:00)
+Plan:     Pro
+`
+	if output := run("whoami"); output != expected {
+		t.Errorf("Unexpected whoami output:\n%s", output)
+	}
+
+	expected = `
+# Team
+
+  - Review budget [Alice Smith]
+  - Book room [Marco Bravo]
+  - Order cake
+`
+	if output := run("tasks", "Team"); output != expected {
+		t.Errorf("Unexpected tasks output:\n%q", output)
+	}
+
+	// Assignees are found by email, or by name if unique, among active collaborators
+	run("add", "task", "-p", "Team", "-a", "ALICE@example.com", "Send invoices")
+	run("add", "task", "-p", "Team", "--assignee", "marco bravo", "Pay invoices")
+	run("add", "task", "-p", "Team", "Buy paper")
+	if strings.Join(assignees, ",") != "102,101," {
+		t.Errorf("Expected tasks assigned to 102, 101 and nobody, got %v", assignees)
+	}
+
+	var data util.TodoistData
+	if err := json.Unmarshal([]byte(collaboratorsSyncResponse), &data); err != nil {
+		t.Fatal(err)
+	}
+	if matches := util.FindCollaborators("6Jf8VQXxpwv56VQ8", "Alice Smith", &data); len(matches) != 2 {
+		t.Errorf("Expected two collaborators named Alice Smith, got %v", matches)
+	}
+	if matches := util.FindCollaborators("6Jf8VQXxpwv56VQ8", "bob@example.com", &data); len(matches) != 0 {
+		t.Errorf("Expected no match for a collaborator removed from the project, got %v", matches)
+	}
+	if matches := util.FindCollaborators("6Jf8VQXxpwv56VQ7", "alice@example.com", &data); len(matches) != 0 {
+		t.Errorf("Expected no match in a project that is not shared, got %v", matches)
+	}
+}
@@ -0,0 +1,384 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	AccountsDirName = "accounts"
+	UserFileName    = "user"
+	// LastAccountFileName holds the account of the last token read from token_command
+	// or the credentials saved by `auth login`, in the accounts directory
+	LastAccountFileName = "last-account"
+	// DefaultAccount holds the cache when there is no token, e.g., offline
+	DefaultAccount = "default"
+)
+
+// cacheAccount is the account the cache belongs to, see SetCacheAccount.
+var cacheAccount = DefaultAccount
+
+// AccountKey returns the key of the account a token belongs to: a hash of the
+// token, so the token itself is not stored, or DefaultAccount if there is none.
+func AccountKey(token string) string {
+	if token == "" {
+		return DefaultAccount
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// CacheAccount returns the key of the account the cache belongs to.
+func CacheAccount() string {
+	return cacheAccount
+}
+
+// CacheAccountUser returns the ID of the user the cache belongs to, or an empty
+// string if it is not known yet.
+func CacheAccountUser() string {
+	cachePath, err := GetCachePath()
+	if err != nil {
+		return ""
+	}
+	return readAccountUser(filepath.Dir(cachePath))
+}
+
+// GetAccountsDir returns the directory that holds a cache directory per account.
+func GetAccountsDir() (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userCacheDir, Prog, AccountsDirName), nil
+}
+
+// SetCacheAccount selects the cache of the account a token belongs to, so each
+// account has its own cache, offline queue, change journal and archive.
+// This should be called by the cmd package during initialization, once the token is known.
+// The first time, the cache shared by all accounts in earlier versions is moved to the
+// account's directory.
+//   - token: the Todoist API token
+func SetCacheAccount(token string) {
+	setCacheAccountKey(AccountKey(token))
+}
+
+// setCacheAccountKey selects the cache of an account, see SetCacheAccount.
+//   - account: the key of the account, see AccountKey
+func setCacheAccountKey(account string) {
+	cacheAccount = account
+	if err := migrateLegacyCache(); err != nil {
+		Warn("Failed to move the cache to the account's directory", err)
+	}
+}
+
+// SelectCacheAccount selects the cache of the account of the configured token, see
+// SetCacheAccount. A token from token_command or the saved credentials is only read
+// when needed, see ConfigType.ResolveToken, so until then the cache is that of the last
+// token read from them, or, if none was read before, the token is read now.
+// This should be called by the cmd package during initialization.
+//   - config: the configuration, with the token, if set by the flag, file or environment
+func SelectCacheAccount(config *ConfigType) {
+	if config.Token != "" {
+		SetCacheAccount(config.Token)
+		return
+	}
+	accountsDir, err := GetAccountsDir()
+	if err == nil {
+		if data, err := os.ReadFile(filepath.Join(accountsDir, LastAccountFileName)); err == nil {
+			if account := strings.TrimSpace(string(data)); account != "" {
+				setCacheAccountKey(account)
+				return
+			}
+		}
+	}
+	cacheAccount = DefaultAccount
+	config.ResolveToken()
+}
+
+// RememberTokenAccount records the account of the token read from token_command or the
+// saved credentials, for SelectCacheAccount to find its cache without reading the token.
+//   - token: the token read, or an empty string if there is none
+//
+// Returns an error, if any.
+func RememberTokenAccount(token string) error {
+	accountsDir, err := GetAccountsDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(accountsDir, 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(accountsDir, LastAccountFileName), []byte(AccountKey(token)+"\n"), 0644)
+}
+
+// legacyAccountFiles are the files shared by all accounts in earlier versions that
+// hold changes of a single account, moved to its directory by adoptLegacyFiles.
+var legacyAccountFiles = []string{QueueFileName, JournalFileName}
+
+// getLegacyDir returns the directory that held the cache shared by all accounts in earlier versions.
+func getLegacyDir() (string, error) {
+	accountsDir, err := GetAccountsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(accountsDir), nil
+}
+
+// migrateLegacyCache moves the cache shared by all accounts in earlier versions to
+// the current account's directory, unless the account has a cache already. The cache
+// may hold another account's data, so it is fully synced again, see LoadCache, and the
+// archive of completed tasks is dropped. The queue and journal are left until the
+// sync shows whose they are, see adoptLegacyFiles.
+// Returns an error, if any.
+func migrateLegacyCache() error {
+	cachePath, err := GetCachePath()
+	if err != nil {
+		return err
+	}
+	accountDir := filepath.Dir(cachePath)
+	legacyDir, err := getLegacyDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(accountDir); err == nil {
+		return nil
+	}
+
+	if _, err := os.Stat(filepath.Join(legacyDir, CacheFileName)); err != nil {
+		for _, name := range legacyAccountFiles {
+			if _, err := os.Stat(filepath.Join(legacyDir, name)); err == nil {
+				Warn(fmt.Sprintf("Left %s of an earlier version in %s, as its account is unknown",
+					name, legacyDir), nil)
+			}
+		}
+		return nil
+	}
+	if err := os.MkdirAll(accountDir, 0755); err != nil {
+		return err
+	}
+	for _, name := range []string{CacheFileName, VersionFileName} {
+		err := os.Rename(filepath.Join(legacyDir, name), filepath.Join(accountDir, name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	err = os.Remove(filepath.Join(legacyDir, ArchiveFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// adoptLegacyFiles moves the offline queue and change journal shared by all accounts in
+// earlier versions to the current account's directory, ahead of its own, once a full sync
+// shows they are the account's: the cache they were kept with, moved by migrateLegacyCache,
+// has some of the account's projects. Otherwise they are left where they are, with a warning,
+// so changes queued for another account are never sent with this account's token.
+// Callers must hold the cache lock.
+//   - legacy: the cache saved before caches were kept per account, as loaded
+//   - synced: the data just synced in full
+//
+// Returns an error, if any.
+func adoptLegacyFiles(legacy *CachedTodoistData, synced *TodoistData) error {
+	legacyDir, err := getLegacyDir()
+	if err != nil {
+		return err
+	}
+	cachePath, err := GetCachePath()
+	if err != nil {
+		return err
+	}
+	accountDir := filepath.Dir(cachePath)
+
+	var names []string
+	for _, name := range legacyAccountFiles {
+		if _, err := os.Stat(filepath.Join(legacyDir, name)); err == nil {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	projects := make(map[string]bool)
+	for _, p := range synced.Projects {
+		projects[p.ID] = true
+	}
+	if !slices.ContainsFunc(legacy.GetProjects(), func(p *PbProject) bool { return projects[p.GetId()] }) {
+		Warn(fmt.Sprintf("Left %s of an earlier version in %s, as they belong to another account",
+			strings.Join(names, " and "), legacyDir), nil)
+		return nil
+	}
+
+	for _, name := range names {
+		legacyPath, path := filepath.Join(legacyDir, name), filepath.Join(accountDir, name)
+		data, err := os.ReadFile(legacyPath)
+		if err != nil {
+			return err
+		}
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			data = append(data, '\n')
+		}
+		own, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := writeFileAtomic(path, append(data, own...), 0600); err != nil {
+			return err
+		}
+		if err := os.Remove(legacyPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readAccountUser returns the ID of the user recorded in an account's directory,
+// or an empty string if there is none.
+func readAccountUser(accountDir string) string {
+	data, err := os.ReadFile(filepath.Join(accountDir, UserFileName))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// accountIsEmpty returns true if an account's directory holds nothing worth keeping,
+// at most a lock file and the user it belongs to.
+func accountIsEmpty(accountDir string) bool {
+	entries, err := os.ReadDir(accountDir)
+	if err != nil {
+		return errors.Is(err, os.ErrNotExist)
+	}
+	for _, entry := range entries {
+		if entry.Name() != LockFileName && entry.Name() != UserFileName {
+			return false
+		}
+	}
+	return true
+}
+
+// otherAccountUsers returns the users of the other accounts with something cached,
+// by their directories.
+//   - accountDir: the current account's directory
+func otherAccountUsers(accountDir string) map[string]string {
+	users := make(map[string]string)
+	entries, _ := os.ReadDir(filepath.Dir(accountDir))
+	for _, entry := range entries {
+		other := filepath.Join(filepath.Dir(accountDir), entry.Name())
+		if !entry.IsDir() || other == accountDir || accountIsEmpty(other) {
+			continue
+		}
+		if userID := readAccountUser(other); userID != "" {
+			users[other] = userID
+		}
+	}
+	return users
+}
+
+// takeOverAccount finds the user the client's token belongs to, if the account has
+// nothing cached yet but other accounts do, so adoptAccount takes over the directory
+// of another token of the same user before the cache is loaded. Failures are left
+// to the sync that follows to report. Callers must hold the cache lock.
+//   - ctx: the context that cancels the request
+func (c *Client) takeOverAccount(ctx context.Context) {
+	cachePath, err := GetCachePath()
+	if err != nil || cacheAccount == DefaultAccount {
+		return
+	}
+	accountDir := filepath.Dir(cachePath)
+	if !accountIsEmpty(accountDir) || len(otherAccountUsers(accountDir)) == 0 {
+		return
+	}
+	syncResp, err := c.makeSyncRequest(ctx, "*", []string{ResourceUser})
+	if err != nil || syncResp.User == nil {
+		return
+	}
+	if err := adoptAccount(ctx, syncResp.User.ID); err != nil {
+		Warn("Failed to record the account of the cache", err)
+	}
+}
+
+// adoptAccount records the user the current account belongs to, as found by the
+// Sync API. If the account has nothing cached yet, but another token of the same user
+// does, e.g., after logging in again, the files of that token's directory move to the
+// account's, so its queue, journal and archive are kept. The cache is fully synced again,
+// as it was saved for another token. Callers must hold the cache lock; the other
+// directory's lock is held while its files move, and left there for the processes
+// waiting for it.
+//   - ctx: the context that cancels the wait for the other directory's lock
+//   - userID: the ID of the user the current token belongs to
+//
+// Returns an error, if any.
+func adoptAccount(ctx context.Context, userID string) error {
+	if userID == "" || cacheAccount == DefaultAccount {
+		return nil
+	}
+	cachePath, err := GetCachePath()
+	if err != nil {
+		return err
+	}
+	accountDir := filepath.Dir(cachePath)
+
+	if accountIsEmpty(accountDir) {
+		for other, otherUser := range otherAccountUsers(accountDir) {
+			if otherUser == userID {
+				if err := moveAccountFiles(ctx, other, accountDir); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	if readAccountUser(accountDir) == userID {
+		return nil
+	}
+	if err := os.MkdirAll(accountDir, 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(accountDir, UserFileName), []byte(userID+"\n"), 0644)
+}
+
+// moveAccountFiles moves the files of an account's directory, other than its lock, to
+// another account's directory, holding the lock of the first.
+//   - ctx: the context that cancels the wait for the lock
+//   - from: the directory the files are moved from
+//   - to: the directory the files are moved to
+//
+// Returns an error, if any.
+func moveAccountFiles(ctx context.Context, from, to string) error {
+	lock, err := lockFile(ctx, filepath.Join(from, LockFileName), DefaultLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	if err := os.MkdirAll(to, 0755); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(from)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == LockFileName {
+			continue
+		}
+		if err := os.Rename(filepath.Join(from, entry.Name()), filepath.Join(to, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
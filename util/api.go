@@ -1,21 +1,17 @@
 package util
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 )
 
-const (
-	TodoistBaseURL = "https://api.todoist.com/api/v1"
-	TodoistSyncURL = "https://api.todoist.com/api/v1/sync"
-)
-
 // ValidColors are the allowed color values for projects
 var ValidColors = map[string]bool{
 	"berry_red":   true,
@@ -50,50 +46,32 @@ type SyncResponse struct {
 	Labels       []TodoistLabel    `json:"labels"`
 	Notes        []TodoistComment  `json:"notes"`
 	ProjectNotes []TodoistComment  `json:"project_notes"`
+	Reminders    []TodoistReminder `json:"reminders"`
+	User         *TodoistUser      `json:"user"`
+
+	Collaborators      []TodoistCollaborator      `json:"collaborators"`
+	CollaboratorStates []TodoistCollaboratorState `json:"collaborator_states"`
 }
 
 // makeSyncRequest makes a POST request to the Sync API endpoint.
-//   - token: the Todoist API token
+//   - ctx: the context that cancels the request
 //   - syncToken: the sync token for incremental sync, or "*" for full sync
 //   - resourceTypes: the list of resource types to fetch
 //
 // Returns a SyncResponse and an error if the request fails.
-func makeSyncRequest(token, syncToken string, resourceTypes []string) (*SyncResponse, error) {
-	client := &http.Client{}
-
+func (c *Client) makeSyncRequest(ctx context.Context, syncToken string, resourceTypes []string) (*SyncResponse, error) {
 	// Construct proper JSON array for resource_types
 	resourceTypesJSON, err := json.Marshal(resourceTypes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal resource types: %w", err)
 	}
-	formData := fmt.Sprintf("sync_token=%s&resource_types=%s", syncToken, string(resourceTypesJSON))
+	form := url.Values{}
+	form.Set("sync_token", syncToken)
+	form.Set("resource_types", string(resourceTypesJSON))
 
-	req, err := http.NewRequest("POST", TodoistSyncURL, strings.NewReader(formData))
+	body, err := c.postForm(ctx, "/sync", form)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create sync request: %w", err)
-	}
-
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make sync request: %w", err)
-	}
-	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
-			Warn("Failed to close response body", cerr)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("sync request failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read sync response body: %w", err)
+		return nil, fmt.Errorf("sync request failed: %w", err)
 	}
 
 	var syncResp SyncResponse
@@ -104,70 +82,220 @@ func makeSyncRequest(token, syncToken string, resourceTypes []string) (*SyncResp
 	return &syncResp, nil
 }
 
-// GetTodoistData retrieves data from the Todoist Sync API with caching.
-//   - token: the Todoist API token
+// Sync API resource types cached by Todoister.
+const (
+	ResourceProjects     = "projects"
+	ResourceSections     = "sections"
+	ResourceItems        = "items"
+	ResourceLabels       = "labels"
+	ResourceNotes        = "notes"
+	ResourceProjectNotes = "project_notes"
+	ResourceReminders    = "reminders"
+
+	ResourceUser               = "user"
+	ResourceCollaborators      = "collaborators"
+	ResourceCollaboratorStates = "collaborator_states"
+)
+
+// AllResources lists every cached resource type, in the order they are requested.
+var AllResources = []string{
+	ResourceProjects, ResourceSections, ResourceItems, ResourceLabels, ResourceNotes, ResourceProjectNotes,
+	ResourceReminders, ResourceUser, ResourceCollaborators, ResourceCollaboratorStates,
+}
+
+// syncGroup is a set of resource types that share a sync token, fetched in a single request.
+type syncGroup struct {
+	token     string
+	resources []string
+}
+
+// groupBySyncToken groups the requested resource types by their cached sync token.
+//   - resourceTypes: the resource types to sync
+//   - tokens: the cached sync token per resource type
 //
-// Returns a pointer to a TodoistData struct with the data.
-// Uses a local Protobuf cache for performance. On first run, performs a full sync.
-// On subsequent runs, performs incremental sync to fetch only changes.
-func GetTodoistData(token string) *TodoistData {
-	if token == "" {
-		Die("Missing Todoist token", nil)
+// Returns the groups in AllResources order; resources never synced get a full sync ("*").
+func groupBySyncToken(resourceTypes []string, tokens map[string]string) []syncGroup {
+	requested := make(map[string]bool)
+	for _, r := range resourceTypes {
+		requested[r] = true
+	}
+	var groups []syncGroup
+	index := make(map[string]int)
+	for _, r := range AllResources {
+		if !requested[r] {
+			continue
+		}
+		token := tokens[r]
+		if token == "" {
+			token = "*"
+		}
+		if i, ok := index[token]; ok {
+			groups[i].resources = append(groups[i].resources, r)
+		} else {
+			index[token] = len(groups)
+			groups = append(groups, syncGroup{token: token, resources: []string{r}})
+		}
 	}
+	return groups
+}
 
-	// 1. Try to load cache
-	cached, err := LoadCache()
-	if err != nil {
-		Warn("Failed to load cache, will perform full sync", err)
+// isFresh returns true if data synced at syncedAt can be used without syncing again,
+// because it is younger than MaxAge, or NoSync is set.
+func (c *Client) isFresh(syncedAt time.Time) bool {
+	if c.Recording || c.FullSync {
+		return false
 	}
+	return c.NoSync || time.Since(syncedAt) < c.MaxAge
+}
 
-	// 2. Determine sync token
-	syncToken := "*" // Full sync by default
-	if cached != nil && cached.SyncToken != "" {
-		syncToken = cached.SyncToken
+// cacheIsFresh returns true if cached data can be used without syncing, as allowed by isFresh.
+//   - cached: the cached data
+//   - resourceTypes: the resource types needed, all of which must have been synced before
+func (c *Client) cacheIsFresh(cached *CachedTodoistData, resourceTypes []string) bool {
+	if cached == nil {
+		return false
+	}
+	tokens := cachedSyncTokens(cached)
+	for _, r := range resourceTypes {
+		if tokens[r] == "" {
+			return false
+		}
 	}
+	return c.isFresh(time.Unix(cached.GetCachedAt(), 0))
+}
 
-	// 3. Make Sync API request
-	resourceTypes := []string{"projects", "sections", "items", "labels", "notes", "project_notes"}
-	syncResp, err := makeSyncRequest(token, syncToken, resourceTypes)
-	if err != nil {
-		// If we have cached data and network fails, warn and use cache
-		if cached != nil {
-			Warn("Failed to sync, using cached data", err)
-			return convertCachedToTodoistData(cached)
+// GetTodoistData retrieves data from the Todoist Sync API with caching.
+//   - ctx: the context that cancels the sync request
+//   - client: the Todoist API client
+//   - resourceTypes: the resource types the command needs, e.g., ResourceProjects;
+//     all of them if none is given
+//
+// Returns a pointer to a TodoistData struct with the data.
+// Uses a local Protobuf cache for performance. The cache keeps a sync token per
+// resource type, so only the requested types are synced: a full sync the first time
+// each type is needed, an incremental sync afterward. Resources not requested are
+// returned as cached, possibly stale or empty.
+// Changes queued offline are sent before syncing; those still queued are applied
+// to the returned data. When offline, or the cache is fresh enough for the client's
+// MaxAge or NoSync, the data comes from the cache alone.
+// The changes each sync brings are recorded in the change journal.
+// The first sync also gets the user, recorded as the owner of the cache, see adoptAccount;
+// a new token of a user with a cache under another token takes it over, see takeOverAccount.
+func GetTodoistData(ctx context.Context, client *Client, resourceTypes ...string) *TodoistData {
+	if !client.Offline && client.AuthToken() == "" {
+		Die("Missing Todoist token, set one in the configuration or run 'todoister auth login'", ErrAuth)
+	}
+	if len(resourceTypes) == 0 {
+		resourceTypes = AllResources
+	}
+
+	// 1. Try to load cache, unless replaying: replays must not depend on local state.
+	// Hold the cache lock until the cache is saved, so concurrent processes don't
+	// overwrite each other's updates.
+	var cached *CachedTodoistData
+	var queue []*QueuedCommand
+	fresh, legacy := false, false
+	if !client.Replaying {
+		lock, err := LockCache(ctx, DefaultLockTimeout)
+		if err != nil {
+			Die("Failed to lock cache", err)
 		}
-		Die("Failed to sync", err)
-	}
-
-	// 4. Merge or replace data
-	var todoistData *TodoistData
-	if syncResp.FullSync || cached == nil {
-		// Full sync: use response directly
-		todoistData = &TodoistData{
-			Projects: syncResp.Projects,
-			Sections: syncResp.Sections,
-			Items:    syncResp.Items,
-			Labels:   syncResp.Labels,
-			Comments: append(syncResp.Notes, syncResp.ProjectNotes...),
+		defer lock.Unlock()
+
+		// A new token takes over the files another token of its user left
+		if !client.Offline && !client.Recording {
+			client.takeOverAccount(ctx)
+		}
+		cached, err = LoadCache()
+		if err != nil {
+			Warn("Failed to load cache, will perform full sync", err)
+		}
+		// A cache of an earlier version, maybe another account's, is replaced in full
+		if legacy = cached != nil && cached.GetAccount() == ""; legacy {
+			resourceTypes = AllResources
 		}
-	} else {
-		// Incremental sync: merge with cached data
-		cachedData := convertCachedToTodoistData(cached)
-		todoistData = mergeData(cachedData, syncResp)
-	}
 
-	// 5. Update cache
-	newCache := convertTodoistDataToCached(todoistData, syncResp.SyncToken)
-	if err := SaveCache(newCache); err != nil {
-		Warn("Failed to save cache", err)
-		// Continue anyway - not fatal
+		// Send the changes queued offline first, so the sync picks them up
+		fresh = client.cacheIsFresh(cached, resourceTypes)
+		queue = client.syncQueue(ctx, !fresh)
+	}
+	if client.Offline && cached == nil {
+		Die("No cached data to work offline with, run a command online first", ErrNetwork)
+	}
+
+	// 2. Determine sync tokens
+	todoistData := &TodoistData{}
+	tokens := make(map[string]string)
+	if cached != nil {
+		todoistData = convertCachedToTodoistData(cached)
+		tokens = cachedSyncTokens(cached)
+	}
+	// Resource types never synced before have no changes to journal
+	unsynced := make(map[string]bool)
+	for _, r := range AllResources {
+		unsynced[r] = tokens[r] == ""
+	}
+	if client.Recording || client.FullSync {
+		// Recordings always start with a full sync so they can be replayed anywhere
+		tokens = make(map[string]string)
+	}
+
+	// 3. Make one Sync API request per sync token and merge the results.
+	// The first sync also gets the user, to record whose cache it is.
+	synced := false
+	syncTypes := resourceTypes
+	if tokens[ResourceUser] == "" && !slices.Contains(resourceTypes, ResourceUser) {
+		syncTypes = append(slices.Clone(resourceTypes), ResourceUser)
+	}
+	groups := groupBySyncToken(syncTypes, tokens)
+	if client.Offline || fresh {
+		groups = nil
+	}
+	for _, group := range groups {
+		syncResp, err := client.makeSyncRequest(ctx, group.token, group.resources)
+		if err != nil {
+			// If we have cached data and network fails, warn and use cache.
+			// An invalid token or an interrupted command is not a network failure,
+			// so report it instead.
+			if cached != nil && !errors.Is(err, ErrAuth) && ctx.Err() == nil {
+				Warn("Failed to sync, using cached data", err)
+				break
+			}
+			Die("Failed to sync", err)
+		}
+		merged := mergeData(todoistData, syncResp, group.resources)
+		if !client.Replaying {
+			journalChanges(todoistData, merged, unsynced)
+		}
+		todoistData = merged
+		for _, r := range group.resources {
+			tokens[r] = syncResp.SyncToken
+		}
+		synced = true
+	}
+
+	// 4. Update cache, unless replaying or nothing changed
+	if synced && !client.Replaying {
+		newCache := convertTodoistDataToCached(todoistData, tokens)
+		if err := SaveCache(newCache); err != nil {
+			Warn("Failed to save cache", err)
+			// Continue anyway - not fatal
+		} else if todoistData.User != nil {
+			if err := adoptAccount(ctx, todoistData.User.ID); err != nil {
+				Warn("Failed to record the account of the cache", err)
+			}
+		}
+		if legacy {
+			if err := adoptLegacyFiles(cached, todoistData); err != nil {
+				Warn("Failed to move the queue and journal to the account's directory", err)
+			}
+		}
 	}
 
-	// 6. Return data
-	return todoistData
+	// 5. Return data, with the changes not sent yet
+	return applyQueue(todoistData, queue)
 }
 
-
 // TaskResponse represents a task creation response from the API
 type TaskResponse struct {
 	ID        string `json:"id"`
@@ -191,6 +319,7 @@ type TaskCreateRequest struct {
 	DueDateTime string `json:"due_datetime,omitempty"`
 	DueString   string `json:"due_string,omitempty"`
 	DueLang     string `json:"due_lang,omitempty"`
+	AssigneeID  string `json:"assignee_id,omitempty"`
 }
 
 // ProjectCreateRequest represents the request body for creating a project
@@ -245,13 +374,13 @@ func ParseDateInput(dateInput string) (*DateParams, error) {
 	}, nil
 }
 
-// CreateTask makes a POST request to create a new task using the REST API v1
-func CreateTask(token, content, projectID string, dateParams *DateParams) (*TaskResponse, error) {
-	client := &http.Client{}
-
+// CreateTask makes a POST request to create a new task using the REST API v1,
+// assigned to the collaborator with ID assigneeID, if not empty
+func (c *Client) CreateTask(ctx context.Context, content, projectID string, dateParams *DateParams, assigneeID string) (*TaskResponse, error) {
 	reqBody := TaskCreateRequest{
-		Content:   content,
-		ProjectID: projectID,
+		Content:    content,
+		ProjectID:  projectID,
+		AssigneeID: assigneeID,
 	}
 
 	// Add due date parameters if provided
@@ -262,90 +391,71 @@ func CreateTask(token, content, projectID string, dateParams *DateParams) (*Task
 		reqBody.DueLang = dateParams.DueLang
 	}
 
-	bodyBytes, err := json.Marshal(reqBody)
+	body, err := c.postJSON(ctx, "/tasks", reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
 
-	url := fmt.Sprintf("%s/tasks", TodoistBaseURL)
-	req, err := http.NewRequest("POST", url, strings.NewReader(string(bodyBytes)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	var task TaskResponse
+	if err := json.Unmarshal(body, &task); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Content-Type", "application/json")
+	return &task, nil
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+// TaskAddArgs returns the item_add command arguments equivalent to CreateTask.
+//   - content: the task content
+//   - projectID: the project ID, or the temp_id of a project created offline
+//   - dateParams: the due date parameters, or nil for no due date
+//   - assigneeID: the ID of the collaborator to assign the task to, or empty for none
+//
+// Returns the command arguments.
+func TaskAddArgs(content, projectID string, dateParams *DateParams, assigneeID string) map[string]interface{} {
+	args := map[string]interface{}{"content": content, "project_id": projectID}
+	if assigneeID != "" {
+		args["responsible_uid"] = assigneeID
 	}
-	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
-			Warn("Failed to close response body", cerr)
+	if dateParams != nil {
+		due := make(map[string]interface{})
+		switch {
+		case dateParams.DueDate != "":
+			due["date"] = dateParams.DueDate
+		case dateParams.DueDateTime != "":
+			due["date"] = dateParams.DueDateTime
+		case dateParams.DueString != "":
+			due["string"] = dateParams.DueString
+			due["lang"] = dateParams.DueLang
 		}
-	}()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+		args["due"] = due
 	}
+	return args
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+// ProjectAddArgs returns the project_add command arguments equivalent to CreateProject.
+// Returns the command arguments.
+func ProjectAddArgs(name, parentID, color string) map[string]interface{} {
+	args := map[string]interface{}{"name": name}
+	if parentID != "" {
+		args["parent_id"] = parentID
 	}
-
-	var task TaskResponse
-	if err := json.Unmarshal(body, &task); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	if color != "" {
+		args["color"] = color
 	}
-
-	return &task, nil
+	return args
 }
 
 // CreateProject makes a POST request to create a new project
-func CreateProject(token, name, parentID, color string) (*ProjectResponse, error) {
-	client := &http.Client{}
-
+func (c *Client) CreateProject(ctx context.Context, name, parentID, color string) (*ProjectResponse, error) {
 	reqBody := ProjectCreateRequest{
 		Name:     name,
 		ParentID: parentID,
 		Color:    color,
 	}
 
-	bodyBytes, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	url := fmt.Sprintf("%s/projects", TodoistBaseURL)
-	req, err := http.NewRequest("POST", url, strings.NewReader(string(bodyBytes)))
+	body, err := c.postJSON(ctx, "/projects", reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
-			Warn("Failed to close response body", cerr)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
 	var project ProjectResponse
@@ -356,62 +466,6 @@ func CreateProject(token, name, parentID, color string) (*ProjectResponse, error
 	return &project, nil
 }
 
-// CompleteTask closes/completes a task using the Sync API item_close command.
-//   - token: Todoist API token
-//   - taskID: The task ID to close
-//
-// Returns an error if the request fails.
-func CompleteTask(token, taskID string) error {
-	client := &http.Client{}
-
-	// Generate UUID for command
-	uuid := generateUUID()
-
-	// Build Sync API command
-	commands := []map[string]interface{}{
-		{
-			"type": "item_close",
-			"uuid": uuid,
-			"args": map[string]string{
-				"id": taskID,
-			},
-		},
-	}
-
-	commandsJSON, err := json.Marshal(commands)
-	if err != nil {
-		return fmt.Errorf("failed to marshal commands: %w", err)
-	}
-
-	// Build form data
-	formData := fmt.Sprintf("commands=%s", url.QueryEscape(string(commandsJSON)))
-
-	req, err := http.NewRequest("POST", TodoistSyncURL, strings.NewReader(formData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to complete task: %w", err)
-	}
-	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
-			Warn("Failed to close response body", cerr)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-	}
-
-	return nil
-}
-
 // generateUUID generates a simple UUID for Sync API commands
 func generateUUID() string {
 	b := make([]byte, 16)
@@ -421,117 +475,3 @@ func generateUUID() string {
 	}
 	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
 }
-
-// DeleteProject deletes a project using the Sync API project_delete command.
-//   - token: Todoist API token
-//   - projectID: The project ID to delete
-//
-// Returns an error if the request fails.
-// Note: This deletes the project and all its descendants.
-func DeleteProject(token, projectID string) error {
-	client := &http.Client{}
-
-	// Generate UUID for command
-	uuid := generateUUID()
-
-	// Build Sync API command
-	commands := []map[string]interface{}{
-		{
-			"type": "project_delete",
-			"uuid": uuid,
-			"args": map[string]string{
-				"id": projectID,
-			},
-		},
-	}
-
-	commandsJSON, err := json.Marshal(commands)
-	if err != nil {
-		return fmt.Errorf("failed to marshal commands: %w", err)
-	}
-
-	// Build form data
-	formData := fmt.Sprintf("commands=%s", url.QueryEscape(string(commandsJSON)))
-
-	req, err := http.NewRequest("POST", TodoistSyncURL, strings.NewReader(formData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to delete project: %w", err)
-	}
-	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
-			Warn("Failed to close response body", cerr)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-	}
-
-	return nil
-}
-
-// DeleteTask deletes a task using the Sync API item_delete command.
-//   - token: Todoist API token
-//   - taskID: The task ID to delete
-//
-// Returns an error if the request fails.
-// Note: This deletes the task and all its sub-tasks.
-func DeleteTask(token, taskID string) error {
-	client := &http.Client{}
-
-	// Generate UUID for command
-	uuid := generateUUID()
-
-	// Build Sync API command
-	commands := []map[string]interface{}{
-		{
-			"type": "item_delete",
-			"uuid": uuid,
-			"args": map[string]string{
-				"id": taskID,
-			},
-		},
-	}
-
-	commandsJSON, err := json.Marshal(commands)
-	if err != nil {
-		return fmt.Errorf("failed to marshal commands: %w", err)
-	}
-
-	// Build form data
-	formData := fmt.Sprintf("commands=%s", url.QueryEscape(string(commandsJSON)))
-
-	req, err := http.NewRequest("POST", TodoistSyncURL, strings.NewReader(formData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to delete task: %w", err)
-	}
-	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
-			Warn("Failed to close response body", cerr)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-	}
-
-	return nil
-}
@@ -0,0 +1,258 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	ArchiveFileName = "completed.pb"
+	// DefaultArchiveSpan is how far back the archive starts, unless asked for earlier tasks
+	DefaultArchiveSpan = 30 * 24 * time.Hour
+	// Todoist returns at most 200 completed tasks per page, over at most 3 months
+	completedPageSize = 200
+	maxCompletedSpan  = 89 * 24 * time.Hour
+)
+
+// completedResponse is a page of completed tasks.
+type completedResponse struct {
+	Items      []TodoistItem `json:"items"`
+	NextCursor string        `json:"next_cursor"`
+}
+
+// GetArchivePath returns the path to the archive of completed tasks, next to the cache.
+func GetArchivePath() (string, error) {
+	cachePath, err := GetCachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(cachePath), ArchiveFileName), nil
+}
+
+// LoadArchive reads the archive of completed tasks. Callers must hold the cache lock.
+// Returns the archive, empty if there is none yet, and an error, if any.
+func LoadArchive() (*CompletedArchive, error) {
+	archivePath, err := GetArchivePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(archivePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &CompletedArchive{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if data, err = openData(data); err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", archivePath, err)
+	}
+	archive := &CompletedArchive{}
+	if err := proto.Unmarshal(data, archive); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", archivePath, err)
+	}
+	return archive, nil
+}
+
+// SaveArchive writes the archive of completed tasks, encrypted if cache encryption is on.
+// Callers must hold the cache lock.
+// Returns an error, if any.
+func SaveArchive(archive *CompletedArchive) error {
+	if err := EnsureCacheDir(); err != nil {
+		return err
+	}
+	archivePath, err := GetArchivePath()
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(archive)
+	if err != nil {
+		return err
+	}
+	if data, err = sealData(data); err != nil {
+		return err
+	}
+	return writeFileAtomic(archivePath, data, 0644)
+}
+
+// fetchCompleted retrieves the tasks completed in a period, page by page, in spans
+// no longer than Todoist allows.
+//   - ctx: the context that cancels the requests
+//   - since: the start of the period
+//   - until: the end of the period
+//
+// Returns the completed tasks, and an error, if any.
+func (c *Client) fetchCompleted(ctx context.Context, since, until time.Time) ([]TodoistItem, error) {
+	var items []TodoistItem
+	for start := since; start.Before(until); start = start.Add(maxCompletedSpan) {
+		end := start.Add(maxCompletedSpan)
+		if end.After(until) {
+			end = until
+		}
+		cursor := ""
+		for {
+			query := url.Values{}
+			query.Set("since", start.UTC().Format(time.RFC3339))
+			query.Set("until", end.UTC().Format(time.RFC3339))
+			query.Set("limit", strconv.Itoa(completedPageSize))
+			if cursor != "" {
+				query.Set("cursor", cursor)
+			}
+			body, err := c.get(ctx, "/tasks/completed/by_completion_date", query)
+			if err != nil {
+				return nil, err
+			}
+			var page completedResponse
+			if err := json.Unmarshal(body, &page); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+			}
+			items = append(items, page.Items...)
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+	}
+	return items, nil
+}
+
+// completionKey identifies a completion: recurring tasks are completed once per
+// occurrence, under the same ID.
+func completionKey(item TodoistItem) string {
+	return item.ID + "@" + item.CompletedAt
+}
+
+// GetCompletedTasks retrieves the tasks completed in a period, from the local archive
+// of completed tasks. The archive is first extended with the tasks completed since its
+// last update, and before its start if the period begins earlier.
+//   - ctx: the context that cancels the requests
+//   - client: the Todoist API client
+//   - since: the start of the period, or the zero time for the start of the archive,
+//     DefaultArchiveSpan ago if there is no archive yet
+//   - until: the end of the period, or the zero time for now
+//
+// Returns the completed tasks, in order of completion.
+// When offline, or the archive is fresh enough for the client's MaxAge or NoSync,
+// the tasks come from the archive alone. Recordings and replays fetch the whole period
+// without touching the archive.
+func GetCompletedTasks(ctx context.Context, client *Client, since, until time.Time) []TodoistItem {
+	if !client.Offline && client.AuthToken() == "" {
+		Die("Missing Todoist token, set one in the configuration or run 'todoister auth login'", ErrAuth)
+	}
+	now := time.Now()
+	if until.IsZero() || until.After(now) {
+		until = now
+	}
+
+	archive := &CompletedArchive{}
+	if client.Recording || client.Replaying {
+		// Like full syncs, so recordings can be replayed anywhere
+		if since.IsZero() {
+			since = now.Add(-DefaultArchiveSpan)
+		}
+		items, err := client.fetchCompleted(ctx, since, until)
+		if err != nil {
+			Die("Failed to get completed tasks", err)
+		}
+		for _, item := range items {
+			archive.Items = append(archive.Items, itemToPb(item))
+		}
+	} else {
+		lock, err := LockCache(ctx, DefaultLockTimeout)
+		if err != nil {
+			Die("Failed to lock cache", err)
+		}
+		defer lock.Unlock()
+
+		if archive, err = LoadArchive(); err != nil {
+			Warn("Failed to load the archive of completed tasks, will fetch it again", err)
+			archive = &CompletedArchive{}
+		}
+		if since.IsZero() {
+			since = now.Add(-DefaultArchiveSpan)
+			if archive.GetUntil() > 0 {
+				since = time.Unix(archive.GetSince(), 0)
+			}
+		}
+		if client.Offline && archive.GetUntil() == 0 {
+			Die("No archived completed tasks to work offline with, run a command online first", ErrNetwork)
+		}
+
+		// Fetch what the archive misses: all of the period the first time, then
+		// the tasks completed before its start or since its last update
+		type span struct{ since, until time.Time }
+		var missing []span
+		switch {
+		case client.Offline:
+		case archive.GetUntil() == 0:
+			missing = append(missing, span{since, now})
+		default:
+			if archiveSince := time.Unix(archive.GetSince(), 0); since.Before(archiveSince) {
+				missing = append(missing, span{since, archiveSince})
+			}
+			if archiveUntil := time.Unix(archive.GetUntil(), 0); !client.isFresh(archiveUntil) {
+				missing = append(missing, span{archiveUntil, now})
+			}
+		}
+
+		known := make(map[string]bool)
+		for _, item := range archive.GetItems() {
+			known[completionKey(itemFromPb(item))] = true
+		}
+		updated := false
+		for _, m := range missing {
+			items, err := client.fetchCompleted(ctx, m.since, m.until)
+			if err != nil {
+				// As with the cache, fall back to the archive unless it's not a network failure
+				if archive.GetUntil() > 0 && !errors.Is(err, ErrAuth) && ctx.Err() == nil {
+					Warn("Failed to get completed tasks, using archived ones", err)
+					break
+				}
+				Die("Failed to get completed tasks", err)
+			}
+			for _, item := range items {
+				if key := completionKey(item); !known[key] {
+					known[key] = true
+					archive.Items = append(archive.Items, itemToPb(item))
+				}
+			}
+			if archive.GetUntil() == 0 || m.since.Unix() < archive.GetSince() {
+				archive.Since = m.since.Unix()
+			}
+			if m.until.Unix() > archive.GetUntil() {
+				archive.Until = m.until.Unix()
+			}
+			updated = true
+		}
+		if updated {
+			if err := SaveArchive(archive); err != nil {
+				Warn("Failed to save the archive of completed tasks", err)
+			}
+		}
+	}
+
+	var completed []TodoistItem
+	for _, pb := range archive.GetItems() {
+		item := itemFromPb(pb)
+		completedAt, err := time.Parse(time.RFC3339, item.CompletedAt)
+		if err != nil || (!completedAt.Before(since) && !completedAt.After(until)) {
+			completed = append(completed, item)
+		}
+	}
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completed[i].CompletedAt < completed[j].CompletedAt
+	})
+	return completed
+}
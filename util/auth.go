@@ -0,0 +1,277 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Default values for the OAuth flow.
+const (
+	DefaultAuthorizeURL = "https://todoist.com/oauth/authorize"
+	DefaultTokenURL     = "https://todoist.com/oauth/access_token"
+	DefaultScope        = "data:read_write,data:delete,project:delete"
+)
+
+// Token sources, as reported by `todoister auth status`.
+const (
+	TokenFromFlag        = "--token flag"
+	TokenFromConfig      = "configuration file or TODOIST_TOKEN"
+	TokenFromCommand     = "token_command"
+	TokenFromCredentials = "auth login"
+)
+
+// Credentials is a token obtained with `todoister auth login`.
+type Credentials struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	Scope       string    `json:"scope"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TodoistUser is the user resource of the Sync API.
+type TodoistUser struct {
+	ID            string          `json:"id"`
+	Email         string          `json:"email"`
+	FullName      string          `json:"full_name"`
+	TzInfo        TodoistTimezone `json:"tz_info"`
+	PremiumStatus string          `json:"premium_status"`
+}
+
+// TodoistTimezone is the timezone of a Todoist user.
+type TodoistTimezone struct {
+	Timezone  string `json:"timezone"`
+	GMTString string `json:"gmt_string"`
+}
+
+// Plans by premium_status of the user resource.
+var plans = map[string]string{
+	"not_premium":             "Beginner",
+	"current_personal_plan":   "Pro",
+	"legacy_personal_plan":    "Pro",
+	"teams_business_member":   "Business",
+	"active_business_account": "Business",
+}
+
+// Plan returns the name of the user's Todoist plan, e.g., Pro.
+func (u *TodoistUser) Plan() string {
+	if plan, ok := plans[u.PremiumStatus]; ok {
+		return plan
+	}
+	return u.PremiumStatus
+}
+
+// tokenResponse is the response of the OAuth token exchange.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	Scope       string `json:"scope"`
+}
+
+// randomString returns a random URL-safe string with n bytes of entropy.
+func randomString(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// pkceChallenge returns the S256 PKCE code challenge for a code verifier.
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Login runs the OAuth 2 authorization-code flow with PKCE.
+// It listens on a loopback port for the redirect, has the user authorize the
+// application in a browser, and exchanges the authorization code for a token.
+//   - ctx: the context that cancels the flow
+//   - client: the API client, used for the token exchange
+//   - config: the OAuth settings
+//   - open: called with the authorization URL to open it in a browser
+//
+// Returns the new credentials and an error, if any. A denied authorization wraps ErrAuth.
+func Login(ctx context.Context, client *Client, config OAuth, open func(authURL string) error) (*Credentials, error) {
+	if config.ClientID == "" || config.ClientSecret == "" {
+		return nil, fmt.Errorf("missing oauth.client_id or oauth.client_secret in the configuration")
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", config.RedirectPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the OAuth redirect: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+
+	state := randomString(24)
+	verifier := randomString(48)
+	query := url.Values{}
+	query.Set("client_id", config.ClientID)
+	query.Set("scope", config.Scope)
+	query.Set("state", state)
+	query.Set("response_type", "code")
+	query.Set("redirect_uri", redirectURI)
+	query.Set("code_challenge", pkceChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+	authURL := config.AuthorizeURL + "?" + query.Encode()
+
+	type callback struct {
+		code string
+		err  error
+	}
+	callbacks := make(chan callback, 1)
+	server := &http.Server{
+		ReadHeaderTimeout: DefaultHeaderTimeout,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/callback" {
+				http.NotFound(w, r)
+				return
+			}
+			params := r.URL.Query()
+			var result callback
+			switch {
+			case params.Get("state") != state:
+				// Not our request, possibly forged
+				result.err = fmt.Errorf("%w: OAuth state mismatch", ErrAuth)
+			case params.Get("error") != "":
+				result.err = fmt.Errorf("%w: authorization failed: %s", ErrAuth, params.Get("error"))
+			case params.Get("code") == "":
+				result.err = fmt.Errorf("%w: no authorization code received", ErrAuth)
+			default:
+				result.code = params.Get("code")
+			}
+			if result.err != nil {
+				http.Error(w, result.err.Error(), http.StatusBadRequest)
+			} else {
+				_, _ = fmt.Fprintf(w, "%s is authorized. You can close this window.\n", Prog)
+			}
+			select {
+			case callbacks <- result:
+			default:
+			}
+		}),
+	}
+	go func() { _ = server.Serve(listener) }()
+	defer func() { _ = server.Close() }()
+
+	if err := open(authURL); err != nil {
+		return nil, err
+	}
+
+	var result callback
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result = <-callbacks:
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	token, err := client.exchangeCode(ctx, config, result.code, redirectURI, verifier)
+	if err != nil {
+		return nil, err
+	}
+	scope := token.Scope
+	if scope == "" {
+		// Todoist doesn't echo the scope, so record the one requested
+		scope = config.Scope
+	}
+	return &Credentials{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		Scope:       scope,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}, nil
+}
+
+// exchangeCode exchanges an authorization code for an access token.
+// Returns the token response and an error, if any.
+func (c *Client) exchangeCode(ctx context.Context, config OAuth, code, redirectURI, verifier string) (*tokenResponse, error) {
+	form := url.Values{}
+	form.Set("client_id", config.ClientID)
+	form.Set("client_secret", config.ClientSecret)
+	form.Set("code", code)
+	form.Set("grant_type", "authorization_code")
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", verifier)
+
+	req, err := c.newRequestURL(ctx, http.MethodPost, config.TokenURL, "application/x-www-form-urlencoded",
+		[]byte(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+
+	token := &tokenResponse{}
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("%w: no access token in response", ErrAuth)
+	}
+	return token, nil
+}
+
+// RevokeToken revokes the client's token (RFC 7009), authenticating with the OAuth client credentials.
+// Returns an error, if any.
+func (c *Client) RevokeToken(ctx context.Context, config OAuth) error {
+	body, err := json.Marshal(map[string]string{"token": c.AuthToken(), "token_type_hint": "access_token"})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := c.newRequestURL(ctx, http.MethodPost, c.endpoint("/revoke"), "application/json", body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(config.ClientID, config.ClientSecret)
+	if _, err := c.do(req); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// GetUser fetches the account that owns the client's token, and records it as the
+// owner of the cache, see adoptAccount.
+// Returns the user and an error, if any.
+func (c *Client) GetUser(ctx context.Context) (*TodoistUser, error) {
+	syncResp, err := c.makeSyncRequest(ctx, "*", []string{ResourceUser})
+	if err != nil {
+		return nil, err
+	}
+	if syncResp.User == nil {
+		return nil, fmt.Errorf("no user in sync response")
+	}
+	if !c.Replaying {
+		lock, err := LockCache(ctx, DefaultLockTimeout)
+		if err == nil {
+			err = adoptAccount(ctx, syncResp.User.ID)
+			lock.Unlock()
+		}
+		if err != nil {
+			Warn("Failed to record the account of the cache", err)
+		}
+	}
+	return syncResp.User, nil
+}
+
+// MaskToken returns a token with all but its last four characters hidden.
+func MaskToken(token string) string {
+	if len(token) <= 4 {
+		return strings.Repeat("*", len(token))
+	}
+	return strings.Repeat("*", 8) + token[len(token)-4:]
+}
@@ -5,10 +5,12 @@
 package util
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"time"
 
 	"google.golang.org/protobuf/proto"
 )
@@ -18,36 +20,25 @@ const (
 	VersionFileName = "version"
 )
 
-// SchemaVersion holds the application version for cache compatibility checking.
-// This should be set by the cmd package during initialization.
-var SchemaVersion string
-
-// GetVersionFilePath returns the path to the version file.
+// GetVersionFilePath returns the path to the version file, next to the cache.
+// Versions before the cache schema was kept in the cache kept it in this file.
 func GetVersionFilePath() (string, error) {
-	userCacheDir, err := os.UserCacheDir()
+	cachePath, err := GetCachePath()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(userCacheDir, Prog, VersionFileName), nil
-}
-
-// getSchemaFromVersion extracts major.minor from a version string like "0.4.0"
-func getSchemaFromVersion(version string) string {
-	parts := strings.Split(version, ".")
-	if len(parts) >= 2 {
-		return parts[0] + "." + parts[1]
-	}
-	return version
+	return filepath.Join(filepath.Dir(cachePath), VersionFileName), nil
 }
 
-// GetCachePath returns the path to the cache file.
+// GetCachePath returns the path to the cache file, in the directory of the account
+// selected by SetCacheAccount.
 // Returns an error if the user cache directory cannot be determined.
 func GetCachePath() (string, error) {
-	userCacheDir, err := os.UserCacheDir()
+	accountsDir, err := GetAccountsDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(userCacheDir, Prog, CacheFileName), nil
+	return filepath.Join(accountsDir, cacheAccount, CacheFileName), nil
 }
 
 // EnsureCacheDir creates the cache directory if it doesn't exist.
@@ -61,33 +52,56 @@ func EnsureCacheDir() error {
 	return os.MkdirAll(cacheDir, 0755)
 }
 
-// LoadCache reads and deserializes the Protobuf cache file.
-// Returns nil if the cache doesn't exist, is corrupted, or schema version mismatch.
-// This allows the caller to proceed with a full sync.
-func LoadCache() (*CachedTodoistData, error) {
-	// First check version compatibility if version file exists
-	versionPath, err := GetVersionFilePath()
-	if err == nil {
-		if data, err := os.ReadFile(versionPath); err == nil {
-			// Parse schema=x.y from file
-			content := strings.TrimSpace(string(data))
-			if strings.HasPrefix(content, "schema=") {
-				cachedSchema := strings.TrimPrefix(content, "schema=")
-				currentSchema := getSchemaFromVersion(SchemaVersion)
-				if cachedSchema != currentSchema {
-					Warn(fmt.Sprintf("Cache schema mismatch (cached: %s, current: %s), will perform full sync", cachedSchema, currentSchema), nil)
-					// Overwrite version file with current schema
-					newContent := fmt.Sprintf("schema=%s\n", currentSchema)
-					if err := os.WriteFile(versionPath, []byte(newContent), 0644); err != nil {
-						Warn("Failed to update version file", err)
-					}
-					return nil, nil // Version mismatch, return nil to trigger full sync
-				}
-			}
-		}
-		// If version file doesn't exist or can't be read, continue loading cache
+// writeFileAtomic writes data to a file through a temporary file in the same directory,
+// synced to disk and renamed over the original, so the file is either old or new, never partial.
+//   - path: the file to write
+//   - data: the file contents
+//   - perm: the file permissions
+//
+// Returns an error, if any.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	// Clean up after any failure; a no-op once renamed
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
 	}
 
+	// Sync the directory so the rename itself survives a crash
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	return nil
+}
+
+// LoadCache reads and deserializes the Protobuf cache file, upgrading it to CacheSchema
+// if an earlier version of Todoister saved it. A cache saved before caches were kept per
+// account is kept, but without sync tokens, so it is fully synced.
+// Returns nil if the cache doesn't exist, is corrupted, belongs to another account,
+// or can't be upgraded.
+// This allows the caller to proceed with a full sync.
+func LoadCache() (*CachedTodoistData, error) {
 	cachePath, err := GetCachePath()
 	if err != nil {
 		return nil, nil // Can't get cache path, return nil to trigger full sync
@@ -104,6 +118,10 @@ func LoadCache() (*CachedTodoistData, error) {
 		Warn("Failed to read cache file, will perform full sync", err)
 		return nil, nil // Can't read cache, return nil to trigger full sync
 	}
+	if data, err = openData(data); err != nil {
+		Warn("Failed to decrypt cache file, will perform full sync", err)
+		return nil, nil // No key, or the wrong one, return nil to trigger full sync
+	}
 
 	// Deserialize protobuf
 	cached := &CachedTodoistData{}
@@ -112,11 +130,132 @@ func LoadCache() (*CachedTodoistData, error) {
 		return nil, nil // Corrupted cache, return nil to trigger full sync
 	}
 
+	// Upgrade it first, as caches of earlier versions have no account
+	if err := migrateCache(cached); err != nil {
+		Warn("Failed to upgrade cache, will perform full sync", err)
+		return nil, nil // No migration path, return nil to trigger full sync
+	}
+
+	switch cached.GetAccount() {
+	case cacheAccount:
+	case "":
+		// Saved before caches were kept per account, and moved to this account's
+		// directory by SetCacheAccount. It may hold another account's data, so
+		// keep it until synced, but sync it in full. The account is set once saved.
+		cached.SyncTokens = nil
+		cached.CachedAt = 0
+	default:
+		// A cache saved for another token may hold another account's data
+		Warn("Cache was saved for another account, will perform full sync", nil)
+		return nil, nil
+	}
+
+	return cached, nil
+}
+
+// ReadCache reads and deserializes the Protobuf cache file. Unlike LoadCache, it
+// doesn't upgrade the cache, and fails if the cache is missing or corrupted.
+// Returns the cached data, and an error, if any.
+func ReadCache() (*CachedTodoistData, error) {
+	cachePath, err := GetCachePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = openData(data); err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", cachePath, err)
+	}
+	cached := &CachedTodoistData{}
+	if err := proto.Unmarshal(data, cached); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", cachePath, err)
+	}
 	return cached, nil
 }
 
-// SaveCache serializes and writes the Protobuf cache file.
-// Also writes the version file if it doesn't exist.
+// ClearCache removes the cache file and its version file, so the next sync is a full one.
+// The offline queue is kept. Callers must hold the cache lock.
+// Returns true if there was a cache to remove, and an error, if any.
+func ClearCache() (bool, error) {
+	removed := false
+	for _, getPath := range []func() (string, error){GetCachePath, GetVersionFilePath} {
+		path, err := getPath()
+		if err != nil {
+			return removed, err
+		}
+		err = os.Remove(path)
+		if err == nil {
+			removed = true
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// VerifyCache checks the referential integrity of cached data: every item, section,
+// reminder and comment must belong to a cached project, item or section, as they refer to.
+// Returns a description of each problem found, or an empty slice if there are none.
+func VerifyCache(cached *CachedTodoistData) []string {
+	problems := make([]string, 0)
+	projects := make(map[string]bool)
+	for _, p := range cached.GetProjects() {
+		projects[p.GetId()] = true
+	}
+	sections := make(map[string]string)
+	for _, s := range cached.GetSections() {
+		sections[s.GetId()] = s.GetProjectId()
+	}
+	items := make(map[string]bool)
+	for _, i := range cached.GetItems() {
+		items[i.GetId()] = true
+	}
+
+	for _, p := range cached.GetProjects() {
+		if p.GetParentId() != "" && !projects[p.GetParentId()] {
+			problems = append(problems, fmt.Sprintf("project %s has unknown parent project %s", p.GetId(), p.GetParentId()))
+		}
+	}
+	for _, s := range cached.GetSections() {
+		if !projects[s.GetProjectId()] {
+			problems = append(problems, fmt.Sprintf("section %s has unknown project %s", s.GetId(), s.GetProjectId()))
+		}
+	}
+	for _, i := range cached.GetItems() {
+		if !projects[i.GetProjectId()] {
+			problems = append(problems, fmt.Sprintf("item %s has unknown project %s", i.GetId(), i.GetProjectId()))
+		}
+		if i.GetSectionId() != "" {
+			if projectID, ok := sections[i.GetSectionId()]; !ok {
+				problems = append(problems, fmt.Sprintf("item %s has unknown section %s", i.GetId(), i.GetSectionId()))
+			} else if projectID != i.GetProjectId() {
+				problems = append(problems, fmt.Sprintf("item %s is in section %s of another project", i.GetId(), i.GetSectionId()))
+			}
+		}
+		if i.GetParentId() != "" && !items[i.GetParentId()] {
+			problems = append(problems, fmt.Sprintf("item %s has unknown parent item %s", i.GetId(), i.GetParentId()))
+		}
+	}
+	for _, r := range cached.GetReminders() {
+		if !items[r.GetItemId()] {
+			problems = append(problems, fmt.Sprintf("reminder %s has unknown item %s", r.GetId(), r.GetItemId()))
+		}
+	}
+	for _, c := range cached.GetComments() {
+		if c.GetTaskId() != "" && !items[c.GetTaskId()] {
+			problems = append(problems, fmt.Sprintf("comment %s has unknown item %s", c.GetId(), c.GetTaskId()))
+		}
+		if c.GetProjectId() != "" && !projects[c.GetProjectId()] {
+			problems = append(problems, fmt.Sprintf("comment %s has unknown project %s", c.GetId(), c.GetProjectId()))
+		}
+	}
+	return problems
+}
+
+// SaveCache serializes and writes the Protobuf cache file, encrypted if cache encryption is on,
+// with the current CacheSchema. Also removes the version file of earlier versions.
 // Returns an error if the cache cannot be saved.
 func SaveCache(data *CachedTodoistData) error {
 	// Ensure cache directory exists
@@ -130,27 +269,67 @@ func SaveCache(data *CachedTodoistData) error {
 	}
 
 	// Serialize protobuf
+	data.Account = cacheAccount
+	data.Schema = CacheSchema
 	bytes, err := proto.Marshal(data)
 	if err != nil {
 		return err
 	}
+	if bytes, err = sealData(bytes); err != nil {
+		return err
+	}
 
-	// Write to file
-	if err := os.WriteFile(cachePath, bytes, 0644); err != nil {
+	// Write to file, atomically so readers never see a partial cache
+	if err := writeFileAtomic(cachePath, bytes, 0644); err != nil {
 		return err
 	}
 
-	// Write version file if it doesn't exist
-	versionPath, err := GetVersionFilePath()
-	if err == nil {
-		if _, err := os.Stat(versionPath); os.IsNotExist(err) {
-			schema := getSchemaFromVersion(SchemaVersion)
-			content := fmt.Sprintf("schema=%s\n", schema)
-			if err := os.WriteFile(versionPath, []byte(content), 0644); err != nil {
-				Warn("Failed to write version file", err)
-			}
+	// The schema is in the cache now
+	if versionPath, err := GetVersionFilePath(); err == nil {
+		if err := os.Remove(versionPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			Warn("Failed to remove version file", err)
 		}
 	}
 
 	return nil
 }
+
+// UpdateCache applies commands that Todoist accepted to the cache, so that reads
+// reflect them before the next sync. The sync tokens are kept, so the next sync
+// returns the same changes again and merges them with no effect.
+//   - ctx: the context that cancels the wait for the cache lock
+//   - commands: the commands accepted
+//   - ids: the real IDs of the resources created, by temp_id
+//
+// Returns an error, if any. Does nothing if there is no cache yet.
+func UpdateCache(ctx context.Context, commands []*SyncCommand, ids map[string]string) error {
+	lock, err := LockCache(ctx, DefaultLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	cached, err := LoadCache()
+	if err != nil || cached == nil {
+		return err
+	}
+
+	before := convertCachedToTodoistData(cached)
+	data := before
+	now := time.Now()
+	for _, command := range commands {
+		id := ids[command.TempID]
+		if command.TempID != "" && id == "" {
+			// Unknown real ID, leave it to the next sync
+			continue
+		}
+		data = applyCommand(data, command, id, now)
+	}
+	// The next sync finds these changes already applied, so journal them now
+	journalChanges(before, data, nil)
+
+	updated := convertTodoistDataToCached(data, cachedSyncTokens(cached))
+	// The cache is no fresher than its last sync
+	updated.CachedAt = cached.GetCachedAt()
+	return SaveCache(updated)
+}
@@ -0,0 +1,302 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Default values for the API client.
+const (
+	DefaultBaseURL        = "https://api.todoist.com/api/v1"
+	DefaultDialTimeout    = 10 * time.Second
+	DefaultHeaderTimeout  = 30 * time.Second
+	DefaultIdleConnection = 90 * time.Second
+)
+
+// Client is a Todoist API client.
+// It owns the API token, the base URL and a single HTTP client whose transport
+// is shared by all requests, so that connections are reused across calls.
+// When Recording, every exchange is saved as a fixture; when Replaying,
+// responses come from fixtures and the local cache is left untouched.
+// When Offline, reads come from the cache and changes are queued, see CommitOrQueue.
+type Client struct {
+	Token      string
+	BaseURL    string
+	UserAgent  string
+	Retry      Retry
+	HTTPClient *http.Client
+	Recording  bool
+	Replaying  bool
+	Offline    bool
+	// FullSync ignores the sync tokens in the cache, syncing everything again
+	FullSync bool
+	// MaxAge is how long cached data is used without syncing; 0 always syncs
+	MaxAge time.Duration
+	// NoSync uses cached data without syncing, regardless of its age
+	NoSync bool
+	// config reads the token when Token is empty, see AuthToken
+	config *ConfigType
+}
+
+// NewTransport returns the HTTP transport used by the API client.
+// Proxies are taken from the standard HTTPS_PROXY and NO_PROXY environment variables.
+func NewTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.DialContext = (&net.Dialer{
+		Timeout:   DefaultDialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = DefaultDialTimeout
+	transport.ResponseHeaderTimeout = DefaultHeaderTimeout
+	transport.IdleConnTimeout = DefaultIdleConnection
+	transport.MaxIdleConnsPerHost = 4
+	return transport
+}
+
+// NewClient creates a Todoist API client from the configuration.
+//   - config: a pointer to the ConfigType struct with the token and API settings
+//   - version: the application version, used in the User-Agent header
+//
+// Returns a pointer to a Client.
+func NewClient(config *ConfigType, version string) *Client {
+	baseURL := config.URL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	client := &Client{
+		Token:     config.Token,
+		BaseURL:   strings.TrimRight(baseURL, "/"),
+		UserAgent: fmt.Sprintf("%s/%s", Prog, version),
+		Retry:     newRetryPolicy(config.Retry),
+		Recording: config.RecordDir != "",
+		Replaying: config.ReplayDir != "",
+		Offline:   config.Offline,
+		MaxAge:    config.MaxAge,
+		NoSync:    config.NoSync,
+		config:    config,
+	}
+	// The token to redact, as read by the time of each request
+	token := func() string { return client.Token }
+
+	var transport http.RoundTripper = NewTransport()
+	switch {
+	case config.ReplayDir != "":
+		replay, err := NewReplayTransport(config.ReplayDir)
+		if err != nil {
+			Die("Failed to load recorded responses", err)
+		}
+		transport = replay
+	case config.RecordDir != "":
+		record, err := NewRecordTransport(config.RecordDir, token, transport)
+		if err != nil {
+			Die("Failed to set up recording", err)
+		}
+		transport = record
+	}
+	if config.Debug {
+		transport = &DebugTransport{Token: token, Next: transport}
+	}
+
+	// No overall timeout: requests are bounded by the command's context, see
+	// --timeout, and stalled connections by the dial and header timeouts
+	client.HTTPClient = &http.Client{
+		Transport: transport,
+	}
+	return client
+}
+
+// AuthToken returns the API token, reading it from token_command or the saved
+// credentials the first time, see ConfigType.ResolveToken.
+// Returns an empty string if there is none.
+func (c *Client) AuthToken() string {
+	if c.Token == "" && c.config != nil {
+		c.config.ResolveToken()
+		c.Token = c.config.Token
+	}
+	return c.Token
+}
+
+// endpoint returns the absolute URL for an API path such as "/sync".
+func (c *Client) endpoint(path string) string {
+	return c.BaseURL + path
+}
+
+// newRequest creates an authenticated API request.
+//   - ctx: the context that cancels the request
+//   - method: the HTTP method
+//   - path: the API path, relative to the base URL
+//   - contentType: the Content-Type of body, or empty if there is no body
+//   - body: the request body, or nil
+//
+// Returns the request and an error, if any.
+func (c *Client) newRequest(ctx context.Context, method, path, contentType string, body []byte) (*http.Request, error) {
+	req, err := c.newRequestURL(ctx, method, c.endpoint(path), contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AuthToken()))
+	return req, nil
+}
+
+// newRequestURL creates an unauthenticated request to an absolute URL,
+// such as the OAuth endpoints, which live outside the API base URL.
+// Takes the same parameters as newRequest, with an absolute URL instead of a path.
+func (c *Client) newRequestURL(ctx context.Context, method, rawURL, contentType string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
+
+// do sends an API request and reads the whole response body.
+// Network errors, rate limiting and transient server errors are retried with
+// exponential backoff as configured in the client's retry policy, honoring
+// the Retry-After header. This is safe for mutations: Sync commands carry a
+// uuid and REST requests an X-Request-Id, so Todoist discards duplicates.
+//   - req: the request, as created by newRequest
+//
+// Returns the response body and an error if the request fails. Error responses
+// are returned as *APIError, and network failures wrap ErrNetwork.
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			// Rewind the request body for the new attempt
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		body, retryAfter, retryable, err := c.send(req)
+		if err == nil {
+			return body, nil
+		}
+		if !retryable || attempt >= c.Retry.MaxAttempts || req.Context().Err() != nil {
+			return nil, err
+		}
+
+		delay := c.Retry.backoff(attempt)
+		if retryAfter >= 0 {
+			if retryAfter > c.Retry.MaxBackoff {
+				// Don't block for longer than the policy allows
+				return nil, err
+			}
+			delay = retryAfter
+		}
+		Warn(fmt.Sprintf("Request to %s failed, retrying in %s (attempt %d of %d)",
+			req.URL.Path, delay.Round(time.Millisecond), attempt+1, c.Retry.MaxAttempts), err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// send makes a single attempt of an API request.
+//   - req: the request, with its body ready to be read
+//
+// Returns the response body, the delay requested by Retry-After or error_extra or -1 if none,
+// whether the failure is transient, and an error if the attempt failed.
+func (c *Client) send(req *http.Request) ([]byte, time.Duration, bool, error) {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			// Cancelled or timed out by the caller, not a network failure
+			return nil, -1, false, ctxErr
+		}
+		if isReplayError(err) {
+			return nil, -1, false, err
+		}
+		return nil, -1, true, fmt.Errorf("failed to make request: %w: %w", ErrNetwork, err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			Warn("Failed to close response body", cerr)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, -1, false, ctxErr
+		}
+		return nil, -1, true, fmt.Errorf("failed to read response body: %w: %w", ErrNetwork, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := parseAPIError(resp.StatusCode, body)
+		retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok {
+			if retryAfter, ok = apiErr.RetryAfter(); !ok {
+				retryAfter = -1
+			}
+		}
+		return nil, retryAfter, isRetryableStatus(resp.StatusCode), apiErr
+	}
+	return body, -1, false, nil
+}
+
+// get sends a GET request to an API path with query parameters.
+// Returns the response body and an error, if any.
+func (c *Client) get(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, path+"?"+query.Encode(), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
+// postForm sends a form-encoded POST request to an API path.
+// Returns the response body and an error, if any.
+func (c *Client) postForm(ctx context.Context, path string, form url.Values) ([]byte, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, path, "application/x-www-form-urlencoded",
+		[]byte(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
+// postJSON sends a POST request with a JSON body to an API path.
+// Returns the response body and an error, if any.
+func (c *Client) postJSON(ctx context.Context, path string, v interface{}) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, path, "application/json", body)
+	if err != nil {
+		return nil, err
+	}
+	// Lets Todoist recognize retried requests as duplicates
+	req.Header.Set("X-Request-Id", generateUUID())
+	return c.do(req)
+}
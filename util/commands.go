@@ -0,0 +1,210 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// SyncCommand is a single Sync API write command.
+type SyncCommand struct {
+	Type   string                 `json:"type"`
+	UUID   string                 `json:"uuid"`
+	TempID string                 `json:"temp_id,omitempty"`
+	Args   map[string]interface{} `json:"args"`
+}
+
+// CommandResult is the outcome of a single command sent in a batch.
+type CommandResult struct {
+	Command *SyncCommand
+	ID      string // Real resource ID for commands that carried a temp_id
+	Err     error  // Nil if the command succeeded
+}
+
+// CommandResults holds the per-command outcome of a committed batch.
+type CommandResults struct {
+	SyncToken     string
+	Results       []CommandResult
+	TempIDMapping map[string]string
+}
+
+// commandsResponse is the Sync API response to a write request.
+type commandsResponse struct {
+	SyncToken     string                     `json:"sync_token"`
+	SyncStatus    map[string]json.RawMessage `json:"sync_status"`
+	TempIDMapping map[string]string          `json:"temp_id_mapping"`
+}
+
+// CommandBatch accumulates Sync API commands to send in a single request.
+// Commands are executed by Todoist in the order they were added, and later
+// commands may reference the temp_id of earlier ones in place of a real ID.
+type CommandBatch struct {
+	Commands []*SyncCommand
+}
+
+// NewCommandBatch returns an empty command batch.
+func NewCommandBatch() *CommandBatch {
+	return &CommandBatch{Commands: make([]*SyncCommand, 0)}
+}
+
+// Add appends a command to the batch.
+//   - commandType: the command type, e.g. "item_close"
+//   - args: the command arguments
+//
+// Returns a pointer to the added SyncCommand.
+func (b *CommandBatch) Add(commandType string, args map[string]interface{}) *SyncCommand {
+	command := &SyncCommand{
+		Type: commandType,
+		UUID: generateUUID(),
+		Args: args,
+	}
+	b.Commands = append(b.Commands, command)
+	return command
+}
+
+// AddWithTempID appends a resource-creating command with a fresh temp_id.
+//   - commandType: the command type, e.g. "item_add"
+//   - args: the command arguments
+//
+// Returns the temp_id, which later commands can use as the resource ID.
+func (b *CommandBatch) AddWithTempID(commandType string, args map[string]interface{}) string {
+	command := b.Add(commandType, args)
+	command.TempID = generateUUID()
+	return command.TempID
+}
+
+// Len returns the number of commands in the batch.
+func (b *CommandBatch) Len() int {
+	return len(b.Commands)
+}
+
+// ItemAdd appends an item_add command.
+// Returns the temp_id of the new task.
+func (b *CommandBatch) ItemAdd(args map[string]interface{}) string {
+	return b.AddWithTempID("item_add", args)
+}
+
+// ItemClose appends an item_close command for a task.
+func (b *CommandBatch) ItemClose(id string) *SyncCommand {
+	return b.Add("item_close", map[string]interface{}{"id": id})
+}
+
+// ItemDelete appends an item_delete command for a task and its sub-tasks.
+func (b *CommandBatch) ItemDelete(id string) *SyncCommand {
+	return b.Add("item_delete", map[string]interface{}{"id": id})
+}
+
+// ProjectAdd appends a project_add command.
+// Returns the temp_id of the new project.
+func (b *CommandBatch) ProjectAdd(args map[string]interface{}) string {
+	return b.AddWithTempID("project_add", args)
+}
+
+// ProjectDelete appends a project_delete command for a project and its descendants.
+func (b *CommandBatch) ProjectDelete(id string) *SyncCommand {
+	return b.Add("project_delete", map[string]interface{}{"id": id})
+}
+
+// ReminderAdd appends a reminder_add command.
+// Returns the temp_id of the new reminder.
+func (b *CommandBatch) ReminderAdd(args map[string]interface{}) string {
+	return b.AddWithTempID("reminder_add", args)
+}
+
+// ReminderDelete appends a reminder_delete command for a reminder.
+func (b *CommandBatch) ReminderDelete(id string) *SyncCommand {
+	return b.Add("reminder_delete", map[string]interface{}{"id": id})
+}
+
+// Commit sends all the commands in a batch in a single Sync API request.
+//   - ctx: the context that cancels the request
+//   - batch: the commands to send
+//
+// Returns the per-command results, and an error only if the request itself fails.
+// Use CommandResults.Err to check whether every command succeeded.
+func (c *Client) Commit(ctx context.Context, batch *CommandBatch) (*CommandResults, error) {
+	commandsJSON, err := json.Marshal(batch.Commands)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal commands: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("commands", string(commandsJSON))
+	body, err := c.postForm(ctx, "/sync", form)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp commandsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal commands response: %w", err)
+	}
+
+	return newCommandResults(batch, &resp), nil
+}
+
+// newCommandResults maps sync_status and temp_id_mapping back to the batch commands.
+func newCommandResults(batch *CommandBatch, resp *commandsResponse) *CommandResults {
+	results := &CommandResults{
+		SyncToken:     resp.SyncToken,
+		Results:       make([]CommandResult, len(batch.Commands)),
+		TempIDMapping: resp.TempIDMapping,
+	}
+	if results.TempIDMapping == nil {
+		results.TempIDMapping = make(map[string]string)
+	}
+
+	for i, command := range batch.Commands {
+		result := CommandResult{Command: command}
+		if command.TempID != "" {
+			result.ID = results.TempIDMapping[command.TempID]
+		}
+
+		status, ok := resp.SyncStatus[command.UUID]
+		if !ok {
+			result.Err = fmt.Errorf("%s: no status reported", command.Type)
+		} else {
+			var s string
+			if json.Unmarshal(status, &s) == nil {
+				if s != "ok" {
+					result.Err = fmt.Errorf("%s: unexpected status %q", command.Type, s)
+				}
+			} else {
+				// Failed commands report an error object in the same format as the API
+				commandErr := &APIError{}
+				if err := json.Unmarshal(status, commandErr); err != nil {
+					result.Err = fmt.Errorf("%s: invalid status: %w", command.Type, err)
+				} else {
+					result.Err = fmt.Errorf("%s: %w", command.Type, commandErr)
+				}
+			}
+		}
+		results.Results[i] = result
+	}
+	return results
+}
+
+// Err returns an error joining the errors of all failed commands, or nil if all succeeded.
+func (r *CommandResults) Err() error {
+	errs := make([]error, 0)
+	for _, result := range r.Results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ResolveID returns the real ID for a temp_id, or the argument itself if it isn't mapped.
+func (r *CommandResults) ResolveID(id string) string {
+	if realID, ok := r.TempIDMapping[id]; ok {
+		return realID
+	}
+	return id
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // Default configuration file name and extension.
@@ -24,10 +25,65 @@ type Export struct {
 	Depth  int
 }
 
+type API struct {
+	URL string
+}
+
+type OAuth struct {
+	ClientID     string
+	ClientSecret string
+	AuthorizeURL string
+	TokenURL     string
+	RedirectPort int
+	Scope        string
+}
+
+type Retry struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+type Cache struct {
+	MaxAge     time.Duration
+	NoSync     bool
+	Sync       bool
+	Passphrase string
+	KeyFile    string
+	KeyCommand string
+}
+
 type ConfigType struct {
-	Token string
+	Token        string
+	TokenSource  string
+	TokenCommand string
+	TokenStore   string
+	// tokenResolved is set once token_command or the saved credentials were read
+	tokenResolved bool
+	Timeout       time.Duration
+	Debug         bool
+	Offline       bool
+	RecordDir     string
+	ReplayDir     string
 	Log
 	Export
+	API
+	OAuth
+	Retry
+	Cache
+}
+
+// ConfigDir returns the directory of the configuration file,
+// $XDG_CONFIG_HOME/todoister or ~/.config/todoister.
+func ConfigDir() (string, error) {
+	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
+		return filepath.Join(xdgConfigHome, Prog), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", Prog), nil
 }
 
 // InitConfig initializes the configuration from the configuration file and environment variables.
@@ -44,13 +100,12 @@ func InitConfig(config *ConfigType) {
 	viper.SetConfigName(ConfigFile)
 	viper.SetConfigType(ConfigFileExt)
 
-	// If $XDG_CONFIG_HOME is set, use it
-	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
-		viper.AddConfigPath(filepath.Join(xdgConfigHome, Prog))
-	} else {
-		// Otherwise use default XDG-compliant ~/.config
-		viper.AddConfigPath(filepath.Join(homeDir, ".config", Prog))
+	// Use $XDG_CONFIG_HOME if set, otherwise default XDG-compliant ~/.config
+	configDir, err := ConfigDir()
+	if err != nil {
+		Die("Error getting configuration directory", err)
 	}
+	viper.AddConfigPath(configDir)
 
 	if err = viper.ReadInConfig(); err != nil {
 		// Fall back to more traditional ~/.todoister.toml
@@ -58,9 +113,91 @@ func InitConfig(config *ConfigType) {
 		_ = viper.ReadInConfig()
 	}
 
+	// Warn about secrets that other users can read
+	if configFile := viper.ConfigFileUsed(); configFile != "" &&
+		(viper.InConfig("token") || viper.InConfig("oauth.client_secret") || viper.InConfig("cache.passphrase")) {
+		if err := CheckConfigPermissions(configFile); err != nil && !os.IsNotExist(err) {
+			Warn("Insecure configuration file", err)
+		}
+	}
+
+	if config.TokenStore == "" {
+		config.TokenStore = viper.GetString("token_store")
+	}
+	if config.TokenCommand == "" {
+		config.TokenCommand = viper.GetString("token_command")
+	}
+
 	// Config.Token may have been set already by the -t, --token flag.
-	if config.Token == "" {
-		config.Token = viper.GetString("token")
+	// Otherwise, take it from the config file or environment; token_command
+	// and the credentials saved by `auth login` are read when needed, see ResolveToken.
+	config.tokenResolved = false
+	if config.Token != "" {
+		config.TokenSource = TokenFromFlag
+	} else if config.Token = viper.GetString("token"); config.Token != "" {
+		config.TokenSource = TokenFromConfig
+	} else {
+		config.TokenSource = ""
+	}
+	// Config.Timeout may have been set already by the --timeout flag.
+	if config.Timeout == 0 {
+		config.Timeout = viper.GetDuration("timeout")
+	}
+	// Config.Debug may have been set already by the --debug flag.
+	if !config.Debug {
+		config.Debug = viper.GetBool("debug")
+	}
+	if config.URL == "" {
+		config.URL = viper.GetString("api.url")
+	}
+	if config.ClientID == "" {
+		config.ClientID = viper.GetString("oauth.client_id")
+	}
+	if config.ClientSecret == "" {
+		config.ClientSecret = viper.GetString("oauth.client_secret")
+	}
+	if config.AuthorizeURL == "" {
+		config.AuthorizeURL = viper.GetString("oauth.authorize_url")
+	}
+	if config.AuthorizeURL == "" {
+		config.AuthorizeURL = DefaultAuthorizeURL
+	}
+	if config.TokenURL == "" {
+		config.TokenURL = viper.GetString("oauth.token_url")
+	}
+	if config.TokenURL == "" {
+		config.TokenURL = DefaultTokenURL
+	}
+	if config.RedirectPort == 0 {
+		config.RedirectPort = viper.GetInt("oauth.redirect_port")
+	}
+	if config.Scope == "" {
+		config.Scope = viper.GetString("oauth.scope")
+	}
+	if config.Scope == "" {
+		config.Scope = DefaultScope
+	}
+	if config.MaxAttempts == 0 {
+		config.MaxAttempts = viper.GetInt("retry.max_attempts")
+	}
+	if config.InitialBackoff == 0 {
+		config.InitialBackoff = viper.GetDuration("retry.initial_backoff")
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = viper.GetDuration("retry.max_backoff")
+	}
+	// Config.MaxAge may have been set already by the --max-age flag, and --sync ignores it.
+	if config.MaxAge == 0 && !config.Sync {
+		config.MaxAge = viper.GetDuration("cache.max_age")
+	}
+	if config.Passphrase == "" {
+		config.Passphrase = viper.GetString("cache.passphrase")
+	}
+	if config.KeyFile == "" {
+		config.KeyFile = viper.GetString("cache.keyfile")
+	}
+	if config.KeyCommand == "" {
+		config.KeyCommand = viper.GetString("cache.key_command")
 	}
 	if config.Name == "" {
 		config.Name, _ = ExpandPath(viper.GetString("log.name"))
@@ -75,3 +212,36 @@ func InitConfig(config *ConfigType) {
 		config.Depth = viper.GetInt("export.depth")
 	}
 }
+
+// ResolveToken reads the token from token_command, or else from the credentials saved
+// by `auth login`, unless the -t, --token flag, the configuration file or the environment
+// set one. Either may run a command, or prompt to unlock the system keyring, so they are
+// only read the first time the token is needed, see Client.AuthToken. The cache is then
+// that of the token's account, remembered for the next commands, see SelectCacheAccount.
+func (config *ConfigType) ResolveToken() {
+	if config.Token != "" || config.tokenResolved {
+		return
+	}
+	config.tokenResolved = true
+	if config.TokenCommand != "" {
+		token, err := RunTokenCommand(config.TokenCommand)
+		if err != nil {
+			Die("Failed to get token", err)
+		}
+		config.Token = token
+		config.TokenSource = TokenFromCommand
+	} else if store, err := NewCredentialStore(config.TokenStore); err != nil {
+		Warn("Failed to load credentials", err)
+		return
+	} else if credentials, err := store.Load(); err != nil {
+		Warn("Failed to load credentials", err)
+		return
+	} else if credentials != nil {
+		config.Token = credentials.AccessToken
+		config.TokenSource = TokenFromCredentials
+	}
+	SetCacheAccount(config.Token)
+	if err := RememberTokenAccount(config.Token); err != nil {
+		Warn("Failed to record the account of the token", err)
+	}
+}
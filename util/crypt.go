@@ -0,0 +1,262 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Encrypted files start with encryptedMagic, then a random salt and nonce; encrypted
+// lines of the queue and the journal start with encryptedLinePrefix, then the same in base64.
+const (
+	encryptedMagic      = "TODOISTER-ENC1\n"
+	encryptedLinePrefix = "enc:"
+	saltSize            = 16
+	keySize             = 32
+	// passphraseIterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+	passphraseIterations = 600000
+	minKeyFileSize       = 16
+)
+
+// ErrCacheKey is returned when encrypted cache files can't be read or written
+// for lack of the right key.
+var ErrCacheKey = errors.New("cache key unavailable")
+
+// cacheEncryption holds the source of the cache key and the keys derived from it.
+// The key is only read when first needed, so key_command only runs for commands
+// that use the cache, and derived once per salt.
+var cacheEncryption struct {
+	passphrase string
+	keyFile    string
+	keyCommand string
+	secret     []byte
+	secretErr  error
+	keys       map[string][]byte
+	// salt is reused for every file written, so the key is derived once per process
+	salt []byte
+}
+
+// SetCacheEncryption selects how the cache, the offline queue, the change journal and
+// the archive of completed tasks are encrypted, if at all.
+// This should be called by the cmd package during initialization.
+//   - config: a pointer to the ConfigType struct with the cache passphrase, keyfile
+//     or key command, at most one of them set
+//
+// Returns an error if more than one is set.
+func SetCacheEncryption(config *ConfigType) error {
+	set := 0
+	for _, source := range []string{config.Passphrase, config.KeyFile, config.KeyCommand} {
+		if source != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return fmt.Errorf("set only one of cache.passphrase, cache.keyfile and cache.key_command")
+	}
+	cacheEncryption.passphrase = config.Passphrase
+	cacheEncryption.keyFile = config.KeyFile
+	cacheEncryption.keyCommand = config.KeyCommand
+	cacheEncryption.secret, cacheEncryption.secretErr = nil, nil
+	cacheEncryption.keys = make(map[string][]byte)
+	cacheEncryption.salt = nil
+	return nil
+}
+
+// CacheEncrypted returns true if cache files are written encrypted.
+func CacheEncrypted() bool {
+	return cacheEncryption.passphrase != "" || cacheEncryption.keyFile != "" || cacheEncryption.keyCommand != ""
+}
+
+// IsEncrypted returns true if the contents of a cache file are encrypted.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedMagic))
+}
+
+// cacheSecret returns the passphrase, the contents of the keyfile or the output
+// of the key command, reading it the first time.
+func cacheSecret() ([]byte, error) {
+	if cacheEncryption.secret != nil || cacheEncryption.secretErr != nil {
+		return cacheEncryption.secret, cacheEncryption.secretErr
+	}
+	var secret []byte
+	var err error
+	switch {
+	case cacheEncryption.passphrase != "":
+		secret = []byte(cacheEncryption.passphrase)
+	case cacheEncryption.keyFile != "":
+		var path string
+		if path, err = ExpandPath(cacheEncryption.keyFile); err == nil {
+			secret, err = os.ReadFile(path)
+		}
+		if err == nil && len(secret) < minKeyFileSize {
+			err = fmt.Errorf("keyfile %s is shorter than %d bytes", path, minKeyFileSize)
+		}
+	case cacheEncryption.keyCommand != "":
+		cmd := exec.Command("sh", "-c", cacheEncryption.keyCommand)
+		cmd.Stdin = os.Stdin   // Let the command ask for a passphrase
+		cmd.Stderr = os.Stderr // and show its prompts and errors
+		var output []byte
+		if output, err = cmd.Output(); err != nil {
+			err = fmt.Errorf("key_command failed: %w", err)
+		} else if secret = bytes.TrimSpace(output); len(secret) == 0 {
+			err = fmt.Errorf("key_command printed no key")
+		}
+	default:
+		err = fmt.Errorf("no cache.passphrase, cache.keyfile or cache.key_command set")
+	}
+	if err != nil {
+		cacheEncryption.secretErr = fmt.Errorf("%w: %w", ErrCacheKey, err)
+	} else {
+		cacheEncryption.secret = secret
+	}
+	return cacheEncryption.secret, cacheEncryption.secretErr
+}
+
+// cacheKey derives the key for a salt, with PBKDF2 from a passphrase, which is slow
+// on purpose, or with HKDF from a keyfile or key command, assumed random enough.
+func cacheKey(salt []byte) ([]byte, error) {
+	if key, ok := cacheEncryption.keys[string(salt)]; ok {
+		return key, nil
+	}
+	secret, err := cacheSecret()
+	if err != nil {
+		return nil, err
+	}
+	var key []byte
+	if cacheEncryption.passphrase != "" {
+		key, err = pbkdf2.Key(sha256.New, string(secret), salt, passphraseIterations, keySize)
+	} else {
+		key, err = hkdf.Key(sha256.New, secret, salt, Prog+" cache", keySize)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cacheEncryption.keys[string(salt)] = key
+	return key, nil
+}
+
+// newGCM returns an AES-256-GCM cipher for a key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealData encrypts the contents of a cache file, if cache encryption is on.
+//   - data: the contents to write
+//
+// Returns the contents as they are if encryption is off, encrypted and authenticated
+// with AES-256-GCM otherwise, and an error, if any.
+func sealData(data []byte) ([]byte, error) {
+	if !CacheEncrypted() {
+		return data, nil
+	}
+	if cacheEncryption.salt == nil {
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		cacheEncryption.salt = salt
+	}
+	key, err := cacheKey(cacheEncryption.salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := append([]byte(encryptedMagic), cacheEncryption.salt...)
+	sealed := append(append([]byte{}, header...), nonce...)
+	// The header is authenticated too, so it can't be tampered with
+	return gcm.Seal(sealed, nonce, data, header), nil
+}
+
+// openData decrypts the contents of a cache file, if encrypted.
+//   - data: the contents read
+//
+// Returns the decrypted contents, the contents as they are if not encrypted, e.g., written
+// before encryption was turned on, and an error wrapping ErrCacheKey if there is no key
+// or it is not the right one.
+func openData(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	if !CacheEncrypted() {
+		return nil, fmt.Errorf("%w: the file is encrypted, but no cache.passphrase, cache.keyfile or cache.key_command is set", ErrCacheKey)
+	}
+	headerSize := len(encryptedMagic) + saltSize
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("truncated encrypted file")
+	}
+	header, salt := data[:headerSize], data[len(encryptedMagic):headerSize]
+	key, err := cacheKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize+gcm.NonceSize() {
+		return nil, fmt.Errorf("truncated encrypted file")
+	}
+	nonce, ciphertext := data[headerSize:headerSize+gcm.NonceSize()], data[headerSize+gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: wrong key, or the file was tampered with", ErrCacheKey)
+	}
+	if cacheEncryption.salt == nil {
+		cacheEncryption.salt = append([]byte{}, salt...)
+	}
+	return plain, nil
+}
+
+// sealLine encrypts a line of the offline queue or the change journal, if cache
+// encryption is on, so lines can still be appended one at a time.
+// Returns the line without its newline, and an error, if any.
+func sealLine(line []byte) ([]byte, error) {
+	if !CacheEncrypted() {
+		return line, nil
+	}
+	sealed, err := sealData(line)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(encryptedLinePrefix + base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// openLine decrypts a line of the offline queue or the change journal, if encrypted.
+// Returns the line, and an error, if any.
+func openLine(line []byte) ([]byte, error) {
+	encoded, ok := strings.CutPrefix(string(line), encryptedLinePrefix)
+	if !ok {
+		return line, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return openData(sealed)
+}
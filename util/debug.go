@@ -0,0 +1,78 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// DebugTransport is an HTTP transport that logs every API call with Debug:
+// method, URL, headers and body of the request, then status, response size and
+// latency. The Authorization header, the token and other secrets are redacted.
+type DebugTransport struct {
+	// Token returns the API token to redact, read only when first needed
+	Token func() string
+	Next  http.RoundTripper
+}
+
+// RoundTrip logs the request, makes it with the next transport and logs the outcome.
+func (t *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	token := t.Token()
+	Debug("API request",
+		"method", req.Method,
+		"url", redact(req.URL.String(), token),
+		"header", redactHeader(req.Header, token),
+		"body", redact(string(body), token))
+
+	start := time.Now()
+	resp, err := t.Next.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		Debug("API request failed", "method", req.Method, "url", redact(req.URL.String(), token),
+			"latency", latency, "error", err)
+		return nil, err
+	}
+
+	// Count the body as it is read, so streaming is not affected
+	resp.Body = &debugBody{
+		ReadCloser: resp.Body,
+		done: func(size int64) {
+			Debug("API response",
+				"method", req.Method,
+				"url", redact(req.URL.String(), token),
+				"status", resp.StatusCode,
+				"size", size,
+				"latency", latency)
+		},
+	}
+	return resp, nil
+}
+
+// debugBody is a response body that reports its size once it has been read and closed.
+type debugBody struct {
+	io.ReadCloser
+	size int64
+	done func(size int64)
+}
+
+func (b *debugBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	return n, err
+}
+
+func (b *debugBody) Close() error {
+	if b.done != nil {
+		b.done(b.size)
+		b.done = nil
+	}
+	return b.ReadCloser.Close()
+}
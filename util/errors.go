@@ -0,0 +1,134 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sentinel errors for the failure classes that scripts may want to tell apart.
+// Use errors.Is to test for them.
+var (
+	ErrAuth        = errors.New("authentication failed")
+	ErrNotFound    = errors.New("not found")
+	ErrRateLimited = errors.New("rate limited")
+	ErrNetwork     = errors.New("network unavailable")
+	ErrAmbiguous   = errors.New("ambiguous match")
+	ErrCacheLocked = errors.New("cache locked")
+)
+
+// Process exit codes, one per failure class.
+const (
+	ExitOK          = 0
+	ExitFailure     = 1
+	ExitUsage       = 2
+	ExitAuth        = 3
+	ExitNotFound    = 4
+	ExitAmbiguous   = 5
+	ExitRateLimited = 6
+	ExitNetwork     = 7
+	ExitTimeout     = 8
+	ExitLocked      = 9
+	ExitInterrupted = 130 // Conventional 128 + SIGINT
+)
+
+// APIError is an error response from the Todoist API.
+// Both REST endpoints and failed Sync commands report errors in this format.
+type APIError struct {
+	Message  string                 `json:"error"`
+	Code     int                    `json:"error_code"`
+	Tag      string                 `json:"error_tag"`
+	HTTPCode int                    `json:"http_code"`
+	Extra    map[string]interface{} `json:"error_extra"`
+}
+
+func (e *APIError) Error() string {
+	var details []string
+	if e.HTTPCode != 0 {
+		details = append(details, fmt.Sprintf("status %d", e.HTTPCode))
+	}
+	if e.Tag != "" {
+		details = append(details, e.Tag)
+	}
+	if e.Code != 0 {
+		details = append(details, fmt.Sprintf("error code %d", e.Code))
+	}
+	if len(details) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, strings.Join(details, ", "))
+}
+
+// Is maps an APIError to the sentinel error for its failure class.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrAuth:
+		return e.HTTPCode == http.StatusUnauthorized || e.HTTPCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.HTTPCode == http.StatusNotFound || e.Tag == "NOT_FOUND"
+	case ErrRateLimited:
+		return e.HTTPCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// RetryAfter returns the delay requested in error_extra.retry_after, if any.
+func (e *APIError) RetryAfter() (time.Duration, bool) {
+	if seconds, ok := e.Extra["retry_after"].(float64); ok && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	return 0, false
+}
+
+// parseAPIError builds an APIError from an error response.
+//   - status: the HTTP status code
+//   - body: the response body, normally Todoist's error JSON
+//
+// Returns a pointer to an APIError; non-JSON bodies become the error message.
+func parseAPIError(status int, body []byte) *APIError {
+	apiErr := &APIError{}
+	if err := json.Unmarshal(body, apiErr); err != nil || apiErr.Message == "" {
+		apiErr = &APIError{Message: strings.TrimSpace(string(body))}
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(status)
+		}
+	}
+	if apiErr.HTTPCode == 0 {
+		apiErr.HTTPCode = status
+	}
+	return apiErr
+}
+
+// ExitCode returns the process exit code for an error.
+// Returns ExitFailure for nil or unclassified errors.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitFailure
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, context.DeadlineExceeded):
+		return ExitTimeout
+	case errors.Is(err, ErrAuth):
+		return ExitAuth
+	case errors.Is(err, ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, ErrAmbiguous):
+		return ExitAmbiguous
+	case errors.Is(err, ErrRateLimited):
+		return ExitRateLimited
+	case errors.Is(err, ErrNetwork):
+		return ExitNetwork
+	case errors.Is(err, ErrCacheLocked):
+		return ExitLocked
+	}
+	return ExitFailure
+}
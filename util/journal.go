@@ -0,0 +1,333 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+)
+
+const JournalFileName = "journal.jsonl"
+
+// Kinds of resources in the change journal.
+const (
+	KindProject = "project"
+	KindSection = "section"
+	KindTask    = "task"
+	KindLabel   = "label"
+	KindComment = "comment"
+)
+
+// Changes recorded in the change journal.
+const (
+	ChangeAdded     = "added"
+	ChangeUpdated   = "updated"
+	ChangeCompleted = "completed"
+	ChangeDeleted   = "deleted"
+)
+
+// JournalEntry is a change to a resource, as found by a sync.
+type JournalEntry struct {
+	At     time.Time `json:"at"`
+	Kind   string    `json:"kind"`
+	Change string    `json:"change"`
+	ID     string    `json:"id"`
+	// Name is the name of a project, section or label, or the content of a task or comment
+	Name      string `json:"name"`
+	ProjectID string `json:"project_id,omitempty"`
+	// Project is the path of the resource's project, e.g., Work/Reports
+	Project string `json:"project,omitempty"`
+	// Fields are the fields an update changed
+	Fields []string `json:"fields,omitempty"`
+}
+
+// GetJournalPath returns the path to the change journal, with one change per line.
+func GetJournalPath() (string, error) {
+	cachePath, err := GetCachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(cachePath), JournalFileName), nil
+}
+
+// LoadJournal reads the changes recorded since a given time.
+//   - since: the time of the oldest change to return
+//
+// Returns the changes in the order they were found, and an error, if any.
+func LoadJournal(since time.Time) ([]JournalEntry, error) {
+	journalPath, err := GetJournalPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(journalPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return make([]JournalEntry, 0), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	entries := make([]JournalEntry, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		text, err := openLine(scanner.Bytes())
+		if errors.Is(err, ErrCacheKey) {
+			return nil, fmt.Errorf("failed to decrypt %s, line %d: %w", journalPath, line, err)
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(text, &entry); err != nil {
+			// A crash may leave the last line partial
+			Warn(fmt.Sprintf("Skipping invalid entry in %s, line %d", journalPath, line), err)
+			continue
+		}
+		if !entry.At.Before(since) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// appendJournal appends changes to the change journal. Callers must hold the cache lock.
+// Returns an error, if any.
+func appendJournal(entries []JournalEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := EnsureCacheDir(); err != nil {
+		return err
+	}
+	journalPath, err := GetJournalPath()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if line, err = sealLine(line); err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	file, err := os.OpenFile(journalPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ignoredFields are the fields whose changes aren't worth journaling: timestamps and
+// positions Todoist sets by itself, and counts that follow from other changes.
+var ignoredFields = map[string]bool{
+	"added_at":      true,
+	"created_at":    true,
+	"updated_at":    true,
+	"child_order":   true,
+	"section_order": true,
+	"note_count":    true,
+}
+
+// emptyList reports whether a JSON value is null or an empty list, which the cache,
+// storing lists as repeated fields, can't tell apart.
+func emptyList(value json.RawMessage) bool {
+	return value == nil || string(value) == "null" || string(value) == "[]"
+}
+
+// changedFields returns the JSON fields that differ between two versions of a resource,
+// other than ignoredFields. A completion time that changes but stays set is ignored too:
+// Todoist's own replaces the local one the cache was written through with.
+func changedFields(before, after interface{}) []string {
+	fields := func(v interface{}) map[string]json.RawMessage {
+		m := make(map[string]json.RawMessage)
+		if data, err := json.Marshal(v); err == nil {
+			_ = json.Unmarshal(data, &m)
+		}
+		return m
+	}
+	b, a := fields(before), fields(after)
+	var changed []string
+	for name, value := range a {
+		if ignoredFields[name] || bytes.Equal(value, b[name]) || (emptyList(value) && emptyList(b[name])) {
+			continue
+		}
+		if name == "completed_at" && string(value) != `""` && string(b[name]) != `""` {
+			continue
+		}
+		changed = append(changed, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok && !ignoredFields[name] && !emptyList(b[name]) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// diffResources finds the resources of one kind added, updated or deleted between two
+// versions of the data.
+//   - kind: the kind of resources, e.g., KindTask
+//   - before: the resources before
+//   - after: the resources after
+//   - id: returns the ID of a resource
+//   - describe: returns the name and project ID of a resource
+//   - at: when the changes were found
+//
+// Returns a journal entry per change, deletions last.
+func diffResources[T any](kind string, before, after []T, id func(T) string,
+	describe func(T) (string, string), at time.Time) []JournalEntry {
+	old := make(map[string]T, len(before))
+	for _, r := range before {
+		old[id(r)] = r
+	}
+
+	var entries []JournalEntry
+	for _, r := range after {
+		entry := JournalEntry{At: at, Kind: kind, ID: id(r)}
+		entry.Name, entry.ProjectID = describe(r)
+		if previous, ok := old[id(r)]; !ok {
+			entry.Change = ChangeAdded
+		} else {
+			delete(old, id(r))
+			if reflect.DeepEqual(previous, r) {
+				continue
+			}
+			if entry.Fields = changedFields(previous, r); len(entry.Fields) == 0 {
+				continue
+			}
+			entry.Change = ChangeUpdated
+		}
+		entries = append(entries, entry)
+	}
+	for _, r := range before {
+		if _, ok := old[id(r)]; ok {
+			entry := JournalEntry{At: at, Kind: kind, ID: id(r), Change: ChangeDeleted}
+			entry.Name, entry.ProjectID = describe(r)
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// diffData finds the changes between two versions of the data.
+//   - before: the data before
+//   - after: the data after
+//   - skip: the resource types not to look at, e.g., those never synced before
+//   - at: when the changes were found
+//
+// Returns a journal entry per change, with the path of each resource's project.
+func diffData(before, after *TodoistData, skip map[string]bool, at time.Time) []JournalEntry {
+	var entries []JournalEntry
+
+	if !skip[ResourceProjects] {
+		entries = append(entries, diffResources(KindProject, before.Projects, after.Projects,
+			func(p TodoistProject) string { return p.ID },
+			func(p TodoistProject) (string, string) { return p.Name, p.ID }, at)...)
+	}
+	if !skip[ResourceSections] {
+		entries = append(entries, diffResources(KindSection, before.Sections, after.Sections,
+			func(s TodoistSection) string { return s.ID },
+			func(s TodoistSection) (string, string) { return s.Name, s.ProjectID }, at)...)
+	}
+	if !skip[ResourceItems] {
+		items := diffResources(KindTask, before.Items, after.Items,
+			func(i TodoistItem) string { return i.ID },
+			func(i TodoistItem) (string, string) { return i.Content, i.ProjectID }, at)
+		// Completing a task is an update worth telling apart
+		completed := make(map[string]bool)
+		for _, item := range after.Items {
+			completed[item.ID] = item.CompletedAt != ""
+		}
+		for i := range items {
+			if items[i].Change == ChangeUpdated && completed[items[i].ID] &&
+				slices.Contains(items[i].Fields, "completed_at") {
+				items[i].Change = ChangeCompleted
+			}
+		}
+		entries = append(entries, items...)
+	}
+	if !skip[ResourceLabels] {
+		entries = append(entries, diffResources(KindLabel, before.Labels, after.Labels,
+			func(l TodoistLabel) string { return l.ID },
+			func(l TodoistLabel) (string, string) { return l.Name, "" }, at)...)
+	}
+
+	// Task comments belong to the project of their task
+	itemProjects := make(map[string]string)
+	for _, data := range []*TodoistData{before, after} {
+		for _, item := range data.Items {
+			itemProjects[item.ID] = item.ProjectID
+		}
+	}
+	comments := func(data *TodoistData) []TodoistComment {
+		var kept []TodoistComment
+		for _, c := range data.Comments {
+			if (c.TaskID != "" && !skip[ResourceNotes]) || (c.TaskID == "" && !skip[ResourceProjectNotes]) {
+				kept = append(kept, c)
+			}
+		}
+		return kept
+	}
+	entries = append(entries, diffResources(KindComment, comments(before), comments(after),
+		func(c TodoistComment) string { return c.ID },
+		func(c TodoistComment) (string, string) {
+			if c.TaskID != "" {
+				return c.Content, itemProjects[c.TaskID]
+			}
+			return c.Content, c.ProjectID
+		}, at)...)
+
+	// A sync filling in the tasks and projects written through doesn't update them
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.Change != ChangeUpdated || !before.WrittenThrough[entry.ID] ||
+			(entry.Kind != KindTask && entry.Kind != KindProject) {
+			kept = append(kept, entry)
+		}
+	}
+	entries = kept
+
+	// Deleted projects are only in the data before
+	paths := ProjectPaths(append(append([]TodoistProject{}, before.Projects...), after.Projects...))
+	for i := range entries {
+		entries[i].Project = paths[entries[i].ProjectID]
+	}
+	return entries
+}
+
+// journalChanges records the changes between two versions of the cached data,
+// warning about any problem. Callers must hold the cache lock.
+//   - before: the data before
+//   - after: the data after
+//   - skip: the resource types not to look at
+func journalChanges(before, after *TodoistData, skip map[string]bool) {
+	entries := diffData(before, after, skip, time.Now().UTC().Truncate(time.Second))
+	if err := appendJournal(entries); err != nil {
+		Warn("Failed to write the change journal", err)
+	}
+}
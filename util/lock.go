@@ -0,0 +1,124 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Default values for the cache lock.
+const (
+	LockFileName       = "todoist.lock"
+	DefaultLockTimeout = 10 * time.Second
+	lockPollInterval   = 50 * time.Millisecond
+)
+
+// CacheLock is an advisory lock that lets one todoister process at a time
+// load, sync and save the cache.
+type CacheLock struct {
+	file *os.File
+}
+
+// GetLockPath returns the path to the cache lock file.
+func GetLockPath() (string, error) {
+	cachePath, err := GetCachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(cachePath), LockFileName), nil
+}
+
+// LockCache takes the cache lock, waiting for other todoister processes to release it.
+//   - ctx: the context that cancels the wait
+//   - timeout: how long to wait for the lock
+//
+// Returns the lock, and an error wrapping ErrCacheLocked if another process
+// still holds it after the timeout.
+func LockCache(ctx context.Context, timeout time.Duration) (*CacheLock, error) {
+	if err := EnsureCacheDir(); err != nil {
+		return nil, err
+	}
+	lockPath, err := GetLockPath()
+	if err != nil {
+		return nil, err
+	}
+	return lockFile(ctx, lockPath, timeout)
+}
+
+// lockFile takes the lock of a cache directory, see LockCache.
+//   - ctx: the context that cancels the wait
+//   - lockPath: the path to the lock file
+//   - timeout: how long to wait for the lock
+//
+// Returns the lock, and an error, if any.
+func lockFile(ctx context.Context, lockPath string, timeout time.Duration) (*CacheLock, error) {
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := unix.Flock(int(file.Fd()), unix.LOCK_EX|unix.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, unix.EWOULDBLOCK) && !errors.Is(err, unix.EINTR) {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", lockPath, err)
+		}
+		if time.Now().After(deadline) {
+			holder := "another todoister process"
+			if pid := lockHolder(file); pid != 0 {
+				holder = fmt.Sprintf("%s (pid %d)", holder, pid)
+			}
+			_ = file.Close()
+			return nil, fmt.Errorf("%w: %s is using the cache, gave up after %s", ErrCacheLocked, holder, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			_ = file.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	// Record the holder, for the error message of processes waiting for the lock
+	if err := file.Truncate(0); err == nil {
+		_, _ = file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &CacheLock{file: file}, nil
+}
+
+// lockHolder returns the PID recorded in the lock file, or 0 if unknown.
+func lockHolder(file *os.File) int {
+	buf := make([]byte, 32)
+	n, _ := file.ReadAt(buf, 0)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
+// Unlock releases the cache lock.
+func (l *CacheLock) Unlock() {
+	if l == nil || l.file == nil {
+		return
+	}
+	_ = l.file.Truncate(0)
+	_ = unix.Flock(int(l.file.Fd()), unix.LOCK_UN)
+	_ = l.file.Close()
+	l.file = nil
+}
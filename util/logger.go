@@ -19,6 +19,9 @@ const (
 var (
 	tty     = isatty()
 	systemd = isSystemdService()
+
+	// debugLogger writes debug entries to stderr; nil unless debugging.
+	debugLogger *slog.Logger
 )
 
 // isatty returns true if the program is running in a terminal.
@@ -112,22 +115,29 @@ func NewFileLogWriter(logfile string, logFileSize int64, logFileCount int) (*Fil
 		logFileSize:  logFileSize,
 		logFileCount: logFileCount,
 	}
-	// Resume writing to the current log file.
+	// Resume writing to the current log file, or start a new one.
 	if fileInfo, err := os.Stat(logfile); err == nil {
 		writer.currentSize = fileInfo.Size()
-		writer.currentFile, err = os.OpenFile(logfile, os.O_WRONLY|os.O_APPEND, 0644)
-		if err != nil {
-			return nil, err
-		}
+	}
+	var err error
+	writer.currentFile, err = os.OpenFile(logfile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
 	}
 	return writer, nil
 }
 
 // InitLogger initializes the logger.
 // - logfile: the path to the log file
-func InitLogger(logfile string) {
+// - debug: if true, write debug entries to stderr and to the log file, even in a terminal
+func InitLogger(logfile string, debug bool) {
+	debugLogger = nil
+	if debug {
+		debugLogger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+
 	// No need to initialize the logger if running in a terminal or as a systemd service.
-	if tty || systemd {
+	if (tty || systemd) && !debug {
 		return
 	}
 
@@ -156,7 +166,11 @@ func InitLogger(logfile string) {
 	if err != nil {
 		Die("Error creating log file writer", err)
 	}
-	slog.SetDefault(slog.New(slog.NewTextHandler(writer, nil)))
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(writer, &slog.HandlerOptions{Level: level})))
 }
 
 // writeLogEntry writes a log entry to the console or log file.
@@ -165,7 +179,9 @@ func InitLogger(logfile string) {
 // - e: an error, if any
 func writeLogEntry(level slog.Level, text string, e error) {
 	var formattedText string
-	if e != nil {
+	if e != nil && text == "" {
+		formattedText = e.Error()
+	} else if e != nil {
 		formattedText = fmt.Sprintf("%s: %v", text, e)
 	} else {
 		formattedText = text
@@ -190,6 +206,18 @@ func writeLogEntry(level slog.Level, text string, e error) {
 	}
 }
 
+// Debug writes a structured debug entry to stderr and to the log file.
+// Does nothing unless the logger was initialized for debugging.
+// - text: the log message
+// - args: alternating keys and values, as in slog.Debug
+func Debug(text string, args ...any) {
+	if debugLogger == nil {
+		return
+	}
+	debugLogger.Debug(text, args...)
+	slog.Debug(text, args...)
+}
+
 func Info(text string) {
 	writeLogEntry(slog.LevelInfo, text, nil)
 }
@@ -202,7 +230,8 @@ func Error(text string, e error) {
 	writeLogEntry(slog.LevelError, text, e)
 }
 
+// Die logs an error and exits with the exit code for its failure class, see ExitCode.
 func Die(text string, e error) {
 	writeLogEntry(slog.LevelError, text, e)
-	os.Exit(1)
+	os.Exit(ExitCode(e))
 }
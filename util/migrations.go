@@ -0,0 +1,91 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// versionedSchema is the schema in the version file of the last release that kept one
+// there, 0.4. Its caches keep their data, but are synced in full again.
+// Caches of earlier releases are not upgraded.
+const versionedSchema = "0.4"
+
+// CacheSchema is the schema of the caches this version of Todoister saves.
+// Bump it when CachedTodoistData changes in a way older caches must be upgraded
+// for, and add the upgrade to cacheMigrations.
+const CacheSchema = int32(len(cacheMigrations))
+
+// cacheMigration upgrades a cache in place from one schema to the next.
+type cacheMigration func(cached *CachedTodoistData) error
+
+// cacheMigrations upgrade a cache one schema at a time: cacheMigrations[n]
+// upgrades schema n to n+1.
+var cacheMigrations = [...]cacheMigration{
+	// 0, a cache of release 0.4, saved before schemas were numbered: a single sync token
+	// for all resource types, superseded by one per resource type. It is dropped, as the
+	// cache was shared by all accounts and is synced in full again, see LoadCache.
+	func(cached *CachedTodoistData) error {
+		cached.SyncToken = ""
+		return nil
+	},
+	// 1, project child_order wasn't cached, and section order was read from the wrong field:
+	// sync projects and sections in full again to get them
+	func(cached *CachedTodoistData) error {
+		delete(cached.SyncTokens, ResourceProjects)
+		delete(cached.SyncTokens, ResourceSections)
+		return nil
+	},
+}
+
+// versionFileSchema returns the schema in the version file of earlier versions, e.g., "0.4",
+// or an empty string if there is none.
+func versionFileSchema() string {
+	versionPath, err := GetVersionFilePath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(versionPath)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(data)), "schema=")
+}
+
+// migrateCache upgrades a cache saved by an earlier version of Todoister to CacheSchema.
+//   - cached: the cache to upgrade, in place
+//
+// Returns an error if there is no way to upgrade it, e.g., it was saved by a later version.
+func migrateCache(cached *CachedTodoistData) error {
+	schema := cached.GetSchema()
+	if schema > CacheSchema {
+		return fmt.Errorf("cache schema %d is newer than %d, the latest this version knows", schema, CacheSchema)
+	}
+	if schema == 0 {
+		if version := versionFileSchema(); version == "" {
+			return errors.New("cache saved by an unknown version")
+		} else if version != versionedSchema {
+			return fmt.Errorf("cache saved by version %s, too old to upgrade", version)
+		}
+	}
+	for ; schema < CacheSchema; schema++ {
+		if err := cacheMigrations[schema](cached); err != nil {
+			return fmt.Errorf("failed to upgrade cache schema %d to %d: %w", schema, schema+1, err)
+		}
+		cached.Schema = schema + 1
+	}
+	return nil
+}
+
+// CheckCacheSchema checks that a cache is of the current CacheSchema, or can be upgraded to it.
+// Returns an error describing why not, if so.
+func CheckCacheSchema(cached *CachedTodoistData) error {
+	return migrateCache(proto.Clone(cached).(*CachedTodoistData))
+}
@@ -1,29 +1,49 @@
 package util
 
-import "strings"
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
 
 // TodoistData as returned by the unified API v1
 type TodoistData struct {
-	Projects []TodoistProject `json:"projects"`
-	Sections []TodoistSection `json:"sections"`
-	Items    []TodoistItem    `json:"items"`
-	Labels   []TodoistLabel   `json:"labels"`
-	Comments []TodoistComment `json:"comments"`
+	Projects           []TodoistProject           `json:"projects"`
+	Sections           []TodoistSection           `json:"sections"`
+	Items              []TodoistItem              `json:"items"`
+	Labels             []TodoistLabel             `json:"labels"`
+	Comments           []TodoistComment           `json:"comments"`
+	Reminders          []TodoistReminder          `json:"reminders"`
+	User               *TodoistUser               `json:"user"`
+	Collaborators      []TodoistCollaborator      `json:"collaborators"`
+	CollaboratorStates []TodoistCollaboratorState `json:"collaborator_states"`
+	// WrittenThrough are the IDs of the tasks and projects commands wrote through to the
+	// cache, as placeholders of what the next sync returns
+	WrittenThrough map[string]bool `json:"-"`
 }
 
 // Projects
 
 type Project struct {
-	Name      string `json:"name"`
-	Color     string `json:"color"`
-	ViewStyle string `json:"view_style"`
+	Name         string `json:"name"`
+	Color        string `json:"color"`
+	ViewStyle    string `json:"view_style"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+	IsFavorite   bool   `json:"is_favorite"`
+	IsArchived   bool   `json:"is_archived"`
+	IsShared     bool   `json:"is_shared"`
+	InboxProject bool   `json:"inbox_project"`
 }
 
 type TodoistProject struct {
 	Project
-	ID        string `json:"id"`
-	ParentID  string `json:"parent_id"`
-	IsDeleted bool   `json:"is_deleted"`
+	ID         string `json:"id"`
+	ParentID   string `json:"parent_id"`
+	ChildOrder int    `json:"child_order"`
+	Shared     bool   `json:"shared"` // The Sync API name for IsShared
+	IsDeleted  bool   `json:"is_deleted"`
 }
 
 type ExportedProject struct {
@@ -45,7 +65,7 @@ type TodoistSection struct {
 	Section
 	ID        string `json:"id"`
 	ProjectID string `json:"project_id"`
-	Order     int    `json:"order"`
+	Order     int    `json:"section_order"`
 	IsDeleted bool   `json:"is_deleted"`
 }
 
@@ -57,12 +77,17 @@ type ExportedSection struct {
 // Tasks (aka Items)
 
 type Task struct {
-	Content     string `json:"content"`
-	Description string `json:"description"`
-	Priority    int    `json:"priority"`
-	ChildOrder  int    `json:"child_order"`
-	Collapsed   bool   `json:"collapsed"`
-	CompletedAt string `json:"completed_at"`
+	Content        string `json:"content"`
+	Description    string `json:"description"`
+	Priority       int    `json:"priority"`
+	ChildOrder     int    `json:"child_order"`
+	Collapsed      bool   `json:"collapsed"`
+	AddedAt        string `json:"added_at"`
+	UpdatedAt      string `json:"updated_at"`
+	CompletedAt    string `json:"completed_at"`
+	ResponsibleUID string `json:"responsible_uid"`
+	AssignedByUID  string `json:"assigned_by_uid"`
+	NoteCount      int    `json:"note_count"`
 }
 
 type TodoistItem struct {
@@ -70,18 +95,23 @@ type TodoistItem struct {
 	ID        string    `json:"id"`
 	ProjectID string    `json:"project_id"`
 	SectionID string    `json:"section_id"`
+	ParentID  string    `json:"parent_id"`
 	Labels    []string  `json:"labels"`
 	Duration  *Duration `json:"duration"`
 	Due       *Due      `json:"due"`
+	Deadline  *Deadline `json:"deadline"`
 	IsDeleted bool      `json:"is_deleted"`
 }
 
 type ExportedTask struct {
 	Task
+	Assignee string             `json:"assignee,omitempty"` // Name of the ResponsibleUID collaborator
+	Subtasks []*ExportedTask    `json:"subtasks"`
 	Labeled  []*ExportedLabel   `json:"labeled"`
 	Comments []*ExportedComment `json:"comments"`
 	Duration *Duration          `json:"duration"`
 	Due      *Due               `json:"due"`
+	Deadline *Deadline          `json:"deadline"`
 }
 
 // Labels
@@ -104,7 +134,9 @@ type ExportedLabel struct {
 // Comments
 
 type Comment struct {
-	Content string `json:"content"`
+	Content   string `json:"content"`
+	PostedAt  string `json:"posted_at"`
+	PostedUID string `json:"posted_uid"`
 }
 
 type TodoistComment struct {
@@ -119,6 +151,43 @@ type ExportedComment struct {
 	Comment
 }
 
+// Reminders
+
+type Reminder struct {
+	Type         string `json:"type"` // absolute, relative or location
+	Due          *Due   `json:"due"`  // When absolute reminders fire
+	MinuteOffset int    `json:"minute_offset"`
+}
+
+type TodoistReminder struct {
+	Reminder
+	ID        string `json:"id"`
+	ItemID    string `json:"item_id"`
+	NotifyUID string `json:"notify_uid"`
+	IsDeleted bool   `json:"is_deleted"`
+}
+
+// Collaborators
+
+type Collaborator struct {
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+	Timezone string `json:"timezone"`
+}
+
+type TodoistCollaborator struct {
+	Collaborator
+	ID string `json:"id"`
+}
+
+// TodoistCollaboratorState is the membership of a collaborator in a shared project.
+type TodoistCollaboratorState struct {
+	ProjectID string `json:"project_id"`
+	UserID    string `json:"user_id"`
+	State     string `json:"state"` // active, invited or deleted
+	IsDeleted bool   `json:"is_deleted"`
+}
+
 // Due dates
 
 type Due struct {
@@ -129,6 +198,13 @@ type Due struct {
 	Timezone    string `json:"timezone"`
 }
 
+// Deadlines
+
+type Deadline struct {
+	Date string `json:"date"`
+	Lang string `json:"lang"`
+}
+
 // Duration
 
 type Duration struct {
@@ -173,6 +249,90 @@ func FindTasksByPrefix(projectID, prefix string, todoistData *TodoistData) []Tod
 	return matches
 }
 
+// FindTask finds the incomplete task of a project whose content starts with a prefix.
+//   - projectPath: the project name or path, e.g., Work/Reports (case-insensitive);
+//     a name alone is a root project
+//   - prefix: the content prefix to match (case-insensitive)
+//   - todoistData: pointer to TodoistData struct
+//
+// Returns the task, and an error wrapping ErrNotFound if there is no such project or task,
+// or ErrAmbiguous, listing the tasks, if more than one task matches.
+func FindTask(projectPath, prefix string, todoistData *TodoistData) (TodoistItem, error) {
+	var projectID string
+	if strings.Contains(projectPath, "/") {
+		projectID = GetProjectIDByPathFromProjects(projectPath, todoistData.Projects)
+	} else {
+		for _, proj := range todoistData.Projects {
+			if strings.EqualFold(proj.Name, projectPath) && proj.ParentID == "" {
+				projectID = proj.ID
+				break
+			}
+		}
+	}
+	if projectID == "" {
+		return TodoistItem{}, fmt.Errorf("project '%s' %w", projectPath, ErrNotFound)
+	}
+
+	matches := FindTasksByPrefix(projectID, prefix, todoistData)
+	if len(matches) == 0 {
+		return TodoistItem{}, fmt.Errorf("task '%s' in project '%s' %w", prefix, projectPath, ErrNotFound)
+	}
+	if len(matches) > 1 {
+		list := ""
+		for _, task := range matches {
+			list += fmt.Sprintf("\n  - %s", task.Content)
+		}
+		return TodoistItem{}, fmt.Errorf("task '%s' is an %w, please provide a more specific task name:%s",
+			prefix, ErrAmbiguous, list)
+	}
+	return matches[0], nil
+}
+
+// CollaboratorNames returns the full name of every collaborator, and of the user, by user ID.
+//   - todoistData: pointer to TodoistData struct
+//
+// Returns the names by ID; empty unless collaborators or the user were synced.
+func CollaboratorNames(todoistData *TodoistData) map[string]string {
+	names := make(map[string]string)
+	for _, c := range todoistData.Collaborators {
+		names[c.ID] = c.FullName
+	}
+	if user := todoistData.User; user != nil {
+		names[user.ID] = user.FullName
+	}
+	return names
+}
+
+// FindCollaborators finds the active collaborators of a shared project by email or name,
+// to assign tasks to.
+//   - projectID: the project ID
+//   - nameOrEmail: the email, or the full name, of the collaborator (case-insensitive)
+//   - todoistData: pointer to TodoistData struct with collaborators and their states
+//
+// Returns the collaborator with that email, or else every collaborator with that name;
+// none if the project is not shared.
+func FindCollaborators(projectID, nameOrEmail string, todoistData *TodoistData) []TodoistCollaborator {
+	members := make(map[string]bool)
+	for _, s := range todoistData.CollaboratorStates {
+		if s.ProjectID == projectID && s.State == "active" {
+			members[s.UserID] = true
+		}
+	}
+
+	matches := make([]TodoistCollaborator, 0)
+	for _, c := range todoistData.Collaborators {
+		if members[c.ID] && strings.EqualFold(c.Email, nameOrEmail) {
+			return []TodoistCollaborator{c}
+		}
+	}
+	for _, c := range todoistData.Collaborators {
+		if members[c.ID] && strings.EqualFold(c.FullName, nameOrEmail) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
 // GetProjectIDByName returns the project ID for a given project name.
 //   - name: the project name
 //   - todoistData: pointer to TodoistData struct as returned by the API
@@ -243,6 +403,24 @@ func GetProjectByPathName(pathname string, project *ExportedProject) (string, *E
 	return actualPathname, p
 }
 
+// ProjectPaths returns the full path of every project, e.g., Work/Project, by ID.
+func ProjectPaths(projects []TodoistProject) map[string]string {
+	byID := make(map[string]TodoistProject)
+	for _, p := range projects {
+		byID[p.ID] = p
+	}
+	paths := make(map[string]string)
+	for _, p := range projects {
+		names := []string{p.Name}
+		// Stop at unknown parents, and at cycles
+		for parent, ok := byID[p.ParentID]; ok && len(names) <= len(projects); parent, ok = byID[parent.ParentID] {
+			names = append([]string{parent.Name}, names...)
+		}
+		paths[p.ID] = strings.Join(names, "/")
+	}
+	return paths
+}
+
 // GetProjectIDByPath returns the Todoist project ID for a given project path.
 //   - pathname: the project pathname as entered by the user (e.g., "Work/Reports")
 //   - todoistData: pointer to TodoistData struct
@@ -308,7 +486,44 @@ func GetProjectByName(name string, project *ExportedProject) (string, *ExportedP
 	return "", nil
 }
 
+// orderedProjects returns the projects in the order the Todoist UI shows them: the Inbox
+// first, then by child_order among their siblings. Ties are broken by ID, so the order
+// doesn't depend on the order they were synced in.
+func orderedProjects(projects []TodoistProject) []TodoistProject {
+	ordered := slices.Clone(projects)
+	slices.SortFunc(ordered, func(a, b TodoistProject) int {
+		if a.InboxProject != b.InboxProject {
+			if a.InboxProject {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(cmp.Compare(a.ChildOrder, b.ChildOrder), strings.Compare(a.ID, b.ID))
+	})
+	return ordered
+}
+
+// orderedSections returns the sections in the order the Todoist UI shows them, by section_order.
+func orderedSections(sections []TodoistSection) []TodoistSection {
+	ordered := slices.Clone(sections)
+	slices.SortFunc(ordered, func(a, b TodoistSection) int {
+		return cmp.Or(cmp.Compare(a.Order, b.Order), strings.Compare(a.ID, b.ID))
+	})
+	return ordered
+}
+
+// orderedItems returns the tasks in the order the Todoist UI shows them, by child_order
+// among their siblings.
+func orderedItems(items []TodoistItem) []TodoistItem {
+	ordered := slices.Clone(items)
+	slices.SortFunc(ordered, func(a, b TodoistItem) int {
+		return cmp.Or(cmp.Compare(a.ChildOrder, b.ChildOrder), strings.Compare(a.ID, b.ID))
+	})
+	return ordered
+}
+
 // HierarchicalData converts TodoistData to a hierarchical structure of Exported* structs.
+// Projects, sections and tasks are in the order the Todoist UI shows them.
 //   - todoistData: a pointer to a TodoistData struct
 //
 // Returns a slice of root ExportedProject pointers.
@@ -316,7 +531,7 @@ func HierarchicalData(todoistData *TodoistData) []*ExportedProject {
 	// Persistent variable to hold the root ExportedProject references.
 	var roots []*ExportedProject
 
-	todoistProjects := todoistData.Projects
+	todoistProjects := orderedProjects(todoistData.Projects)
 
 	// Map to hold references to each project by ID for easy lookup.
 	var projectMap = make(map[string]*ExportedProject)
@@ -326,6 +541,7 @@ func HierarchicalData(todoistData *TodoistData) []*ExportedProject {
 		p := new(ExportedProject)
 		// Copy common fields from TodoistProject to ExportedProject.
 		p.Project = project.Project
+		p.IsShared = project.IsShared || project.Shared
 		p.Subprojects = make([]*ExportedProject, 0)
 		p.Sections = make([]*ExportedSection, 0)
 		p.Tasks = make([]*ExportedTask, 0)
@@ -334,17 +550,17 @@ func HierarchicalData(todoistData *TodoistData) []*ExportedProject {
 
 	// Build the hierarchy by linking Subprojects to their parent Projects.
 	for _, project := range todoistProjects {
-		if project.ParentID == "" {
-			// If there's no ParentID, it's a root project.
+		parent, hasParent := projectMap[project.ParentID]
+		if project.ParentID == "" || !hasParent {
+			// If there's no ParentID (or the parent is unknown), it's a root project.
 			roots = append(roots, projectMap[project.ID])
 		} else {
 			// Otherwise, it's a Subproject, so add it to its parent's Subprojects slice.
-			projectMap[project.ParentID].Subprojects =
-				append(projectMap[project.ParentID].Subprojects, projectMap[project.ID])
+			parent.Subprojects = append(parent.Subprojects, projectMap[project.ID])
 		}
 	}
 
-	todoistSections := todoistData.Sections
+	todoistSections := orderedSections(todoistData.Sections)
 
 	// Map to hold references to each section by ID for easy lookup.
 	var sectionMap = make(map[string]*ExportedSection)
@@ -359,20 +575,26 @@ func HierarchicalData(todoistData *TodoistData) []*ExportedProject {
 	}
 
 	// Add to the hierarchy by linking Sections to their parent Projects.
+	// Resource types are synced separately, so skip any whose parent is not cached yet.
 	for _, section := range todoistSections {
-		projectMap[section.ProjectID].Sections =
-			append(projectMap[section.ProjectID].Sections, sectionMap[section.ID])
+		if project, exists := projectMap[section.ProjectID]; exists {
+			project.Sections = append(project.Sections, sectionMap[section.ID])
+		}
 	}
 
-	todoistItems := todoistData.Items
+	todoistItems := orderedItems(todoistData.Items)
 
 	// Map to hold references to each item (task) by ID for easy lookup.
 	var taskMap = make(map[string]*ExportedTask)
 
+	// Names of the collaborators tasks are assigned to.
+	assignees := CollaboratorNames(todoistData)
+
 	// Initialize taskMap with common Task fields and empty Task slices.
 	for _, item := range todoistItems {
 		t := new(ExportedTask)
 		t.Task = item.Task // Copy common fields from TodoistItem to ExportedTask
+		t.Assignee = assignees[item.ResponsibleUID]
 
 		if item.Duration != nil && item.Duration.Amount > 0 {
 			t.Duration = new(Duration)
@@ -384,22 +606,32 @@ func HierarchicalData(todoistData *TodoistData) []*ExportedProject {
 			// Copy common fields from due date to ExportedTask.
 			*t.Due = *item.Due
 		}
+		if item.Deadline != nil && item.Deadline.Date != "" {
+			t.Deadline = new(Deadline)
+			// Copy common fields from deadline to ExportedTask.
+			*t.Deadline = *item.Deadline
+		}
 
+		t.Subtasks = make([]*ExportedTask, 0)
 		t.Labeled = make([]*ExportedLabel, 0)
 		t.Comments = make([]*ExportedComment, 0)
 		taskMap[item.ID] = t
 	}
 
-	// Add to the hierarchy by linking Tasks to their parent Projects or Sections.
+	// Add to the hierarchy by linking Subtasks to their parent Tasks, and the
+	// remaining Tasks to their parent Projects or Sections.
 	for _, item := range todoistItems {
-		if item.SectionID == "" {
+		if parent, exists := taskMap[item.ParentID]; exists && item.ParentID != item.ID {
+			// If there's a known ParentID, it's a subtask.
+			parent.Subtasks = append(parent.Subtasks, taskMap[item.ID])
+		} else if item.SectionID == "" {
 			// If there's no SectionID, it's a task attached to the project.
-			projectMap[item.ProjectID].Tasks =
-				append(projectMap[item.ProjectID].Tasks, taskMap[item.ID])
-		} else {
+			if project, exists := projectMap[item.ProjectID]; exists {
+				project.Tasks = append(project.Tasks, taskMap[item.ID])
+			}
+		} else if section, exists := sectionMap[item.SectionID]; exists {
 			// Otherwise, it's attached to a section, so add it to the section's Tasks slice.
-			sectionMap[item.SectionID].Tasks =
-				append(sectionMap[item.SectionID].Tasks, taskMap[item.ID])
+			section.Tasks = append(section.Tasks, taskMap[item.ID])
 		}
 	}
 
@@ -419,8 +651,8 @@ func HierarchicalData(todoistData *TodoistData) []*ExportedProject {
 	// Add to the hierarchy by linking Labels to their respective Tasks.
 	for _, item := range todoistItems {
 		for _, label := range item.Labels {
-			if label != "" {
-				taskMap[item.ID].Labeled = append(taskMap[item.ID].Labeled, labelMap[label])
+			if l, exists := labelMap[label]; exists {
+				taskMap[item.ID].Labeled = append(taskMap[item.ID].Labeled, l)
 			}
 		}
 	}
@@ -0,0 +1,315 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Default values for the offline queue.
+const (
+	QueueFileName = "queue.jsonl"
+	// Todoist accepts at most 100 commands per Sync request
+	maxCommandsPerRequest = 100
+)
+
+// QueuedCommand is a change made offline, waiting to be sent to Todoist.
+type QueuedCommand struct {
+	Command  *SyncCommand `json:"command"`
+	Summary  string       `json:"summary"`
+	QueuedAt time.Time    `json:"queued_at"`
+	// Error is why Todoist rejected the command. Rejected commands are held
+	// in the queue, and not sent again until retried with `todoister queue retry`.
+	Error string `json:"error,omitempty"`
+}
+
+// Pending returns true if the command will be sent on the next sync.
+func (q *QueuedCommand) Pending() bool {
+	return q.Error == ""
+}
+
+// GetQueuePath returns the path to the offline queue, a journal with one queued command per line.
+func GetQueuePath() (string, error) {
+	cachePath, err := GetCachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(cachePath), QueueFileName), nil
+}
+
+// LoadQueue reads the offline queue. Callers must hold the cache lock.
+// Returns the queued commands in order, an empty slice if there are none, and an error, if any.
+func LoadQueue() ([]*QueuedCommand, error) {
+	queuePath, err := GetQueuePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(queuePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return make([]*QueuedCommand, 0), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	queue := make([]*QueuedCommand, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		text, err := openLine(scanner.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s, line %d: %w", queuePath, line, err)
+		}
+		entry := &QueuedCommand{}
+		if err := json.Unmarshal(text, entry); err != nil || entry.Command == nil {
+			return nil, fmt.Errorf("invalid entry in %s, line %d", queuePath, line)
+		}
+		queue = append(queue, entry)
+	}
+	return queue, scanner.Err()
+}
+
+// SaveQueue replaces the offline queue, removing the file if the queue is empty.
+// Callers must hold the cache lock.
+// Returns an error, if any.
+func SaveQueue(queue []*QueuedCommand) error {
+	queuePath, err := GetQueuePath()
+	if err != nil {
+		return err
+	}
+	if len(queue) == 0 {
+		if err := os.Remove(queuePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range queue {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if line, err = sealLine(line); err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+	return writeFileAtomic(queuePath, buf.Bytes(), 0600)
+}
+
+// EnqueueCommands appends the commands of a batch to the offline queue.
+//   - ctx: the context that cancels the wait for the cache lock
+//   - batch: the commands to queue
+//   - summary: describes the change in `todoister queue`
+//
+// Returns an error, if any.
+func EnqueueCommands(ctx context.Context, batch *CommandBatch, summary string) error {
+	lock, err := LockCache(ctx, DefaultLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	queuePath, err := GetQueuePath()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, command := range batch.Commands {
+		line, err := json.Marshal(&QueuedCommand{Command: command, Summary: summary, QueuedAt: now})
+		if err != nil {
+			return err
+		}
+		if line, err = sealLine(line); err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	// Append whole lines and sync, so a crash loses at most this change
+	file, err := os.OpenFile(queuePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// CommitOrQueue sends a batch of commands, or adds it to the offline queue if the client
+// is offline or the network is unavailable. Changes are only ever sent as Sync commands,
+// whose uuid lets Todoist discard the queued copy of a request it accepted before failing.
+//   - ctx: the context that cancels the request
+//   - batch: the commands to send
+//   - summary: describes the change in `todoister queue`
+//
+// Returns true if the commands were queued, and an error if they could be neither sent
+// nor queued, or if Todoist rejected them. Commands sent are written through to the cache.
+func (c *Client) CommitOrQueue(ctx context.Context, batch *CommandBatch, summary string) (bool, error) {
+	if !c.Offline {
+		results, err := c.Commit(ctx, batch)
+		if err == nil {
+			err = results.Err()
+		}
+		if err == nil && !c.Replaying {
+			if err := UpdateCache(ctx, batch.Commands, results.TempIDMapping); err != nil {
+				Warn("Failed to update cache", err)
+			}
+		}
+		// Replays must not depend on local state, so never queue during one
+		if err == nil || !errors.Is(err, ErrNetwork) || c.Replaying || ctx.Err() != nil {
+			return false, err
+		}
+		Warn("Network unavailable, queuing the change for the next sync", err)
+	}
+	if err := EnqueueCommands(ctx, batch, summary); err != nil {
+		return false, fmt.Errorf("failed to queue change: %w", err)
+	}
+	return true, nil
+}
+
+// flushQueue sends the pending commands of the offline queue, in order, and removes
+// the ones Todoist accepted. Commands that Todoist rejects are held in the queue with
+// their error. Callers must hold the cache lock.
+//   - ctx: the context that cancels the requests
+//   - queue: the offline queue, as loaded by LoadQueue
+//
+// Returns the updated queue and an error if a request failed; the queue then keeps
+// every command that was not sent.
+func (c *Client) flushQueue(ctx context.Context, queue []*QueuedCommand) ([]*QueuedCommand, error) {
+	var pending []*QueuedCommand
+	for _, entry := range queue {
+		if entry.Pending() {
+			pending = append(pending, entry)
+		}
+	}
+
+	sent := make(map[*QueuedCommand]bool)
+	tempIDs := make(map[string]string)
+	var requestErr error
+	for start := 0; start < len(pending); start += maxCommandsPerRequest {
+		chunk := pending[start:min(start+maxCommandsPerRequest, len(pending))]
+		batch := NewCommandBatch()
+		for _, entry := range chunk {
+			resolveTempIDs(entry.Command.Args, tempIDs)
+			batch.Commands = append(batch.Commands, entry.Command)
+		}
+
+		results, err := c.Commit(ctx, batch)
+		if err != nil {
+			requestErr = err
+			break
+		}
+		for tempID, id := range results.TempIDMapping {
+			tempIDs[tempID] = id
+		}
+		for i, result := range results.Results {
+			if result.Err != nil {
+				chunk[i].Error = result.Err.Error()
+			} else {
+				sent[chunk[i]] = true
+			}
+		}
+	}
+
+	remaining := make([]*QueuedCommand, 0, len(queue)-len(sent))
+	for _, entry := range queue {
+		if !sent[entry] {
+			// Later commands may still refer to resources created by the ones sent
+			resolveTempIDs(entry.Command.Args, tempIDs)
+			remaining = append(remaining, entry)
+		}
+	}
+	return remaining, requestErr
+}
+
+// resolveTempIDs replaces the temp_ids in command arguments with the real IDs they were mapped to.
+func resolveTempIDs(args map[string]interface{}, tempIDs map[string]string) {
+	for key, value := range args {
+		if s, ok := value.(string); ok {
+			if id, mapped := tempIDs[s]; mapped {
+				args[key] = id
+			}
+		}
+	}
+}
+
+// syncQueue flushes the offline queue before a sync, warning about any problem.
+// Callers must hold the cache lock.
+//   - ctx: the context that cancels the requests
+//   - send: false to only load the queue, when not syncing
+//
+// Returns the commands still queued.
+func (c *Client) syncQueue(ctx context.Context, send bool) []*QueuedCommand {
+	queue, err := LoadQueue()
+	if err != nil {
+		Warn("Failed to load the offline queue", err)
+		return nil
+	}
+	if c.Offline || !send || len(queue) == 0 {
+		return queue
+	}
+
+	held := 0
+	for _, entry := range queue {
+		if !entry.Pending() {
+			held++
+		}
+	}
+	if held == len(queue) {
+		return queue
+	}
+
+	remaining, err := c.flushQueue(ctx, queue)
+	if saveErr := SaveQueue(remaining); saveErr != nil {
+		Warn("Failed to save the offline queue", saveErr)
+	}
+	if err != nil {
+		Warn("Failed to send queued changes, will retry on the next sync", err)
+	}
+	rejected := -held
+	for _, entry := range remaining {
+		if !entry.Pending() {
+			rejected++
+		}
+	}
+	if rejected > 0 {
+		Warn(fmt.Sprintf("Todoist rejected %d queued changes, see 'todoister queue'", rejected), nil)
+	}
+	return remaining
+}
+
+// applyQueue applies the pending commands of the offline queue to synced data, so
+// reads reflect changes not yet sent. Resources created offline keep their temp_ids.
+//   - data: the synced data
+//   - queue: the offline queue
+//
+// Returns the data with the changes applied.
+func applyQueue(data *TodoistData, queue []*QueuedCommand) *TodoistData {
+	for _, entry := range queue {
+		if entry.Pending() {
+			data = applyCommand(data, entry.Command, entry.Command.TempID, entry.QueuedAt)
+		}
+	}
+	return data
+}
@@ -0,0 +1,342 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces secrets in recorded fixtures and debug output.
+const Redacted = "REDACTED"
+
+// fixtureNameRegex matches the names of fixture files, e.g. 0001.json.
+var fixtureNameRegex = regexp.MustCompile(`^\d{4}\.json$`)
+
+// Secrets that may appear in request or response bodies, in JSON or form encoding.
+var (
+	jsonSecretRegex = regexp.MustCompile(`("(?:access_token|client_secret|code_verifier|token)"\s*:\s*")[^"]*(")`)
+	formSecretRegex = regexp.MustCompile(`((?:^|&)(?:access_token|client_secret|code|code_verifier|token)=)[^&]*`)
+)
+
+// Fixture is a recorded API request and its response, stored as an indented JSON file.
+type Fixture struct {
+	Request  FixtureMessage `json:"request"`
+	Response FixtureMessage `json:"response"`
+}
+
+// FixtureMessage is the recorded part of a request or response.
+// JSON bodies are stored as is for readability, anything else as text.
+type FixtureMessage struct {
+	Method string            `json:"method,omitempty"`
+	Path   string            `json:"path,omitempty"`
+	Status int               `json:"status,omitempty"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   json.RawMessage   `json:"body,omitempty"`
+	Text   string            `json:"text,omitempty"`
+}
+
+// replayError is a mismatch between a request and the recorded fixtures.
+// It is never worth retrying.
+type replayError struct {
+	msg string
+}
+
+func (e *replayError) Error() string {
+	return e.msg
+}
+
+// redact removes the token and any other secrets from a string.
+//   - s: the string to redact
+//   - token: the API token, or empty
+func redact(s, token string) string {
+	if token != "" {
+		s = strings.ReplaceAll(s, token, Redacted)
+	}
+	s = jsonSecretRegex.ReplaceAllString(s, "${1}"+Redacted+"${2}")
+	return formSecretRegex.ReplaceAllString(s, "${1}"+Redacted)
+}
+
+// redactHeader returns a copy of a header as a flat map with secrets redacted.
+func redactHeader(header http.Header, token string) map[string]string {
+	flat := make(map[string]string)
+	for name := range header {
+		if http.CanonicalHeaderKey(name) == "Authorization" {
+			// Keep the scheme, e.g., Bearer or Basic
+			scheme, _, _ := strings.Cut(header.Get(name), " ")
+			flat[name] = scheme + " " + Redacted
+		} else {
+			flat[name] = redact(header.Get(name), token)
+		}
+	}
+	return flat
+}
+
+// fixtureHeader returns a header for a fixture, without the fields that
+// differ on every run or after reformatting the body.
+func fixtureHeader(header http.Header, token string) map[string]string {
+	flat := redactHeader(header, token)
+	for _, name := range []string{"User-Agent", "X-Request-Id", "Date", "Content-Length"} {
+		delete(flat, name)
+	}
+	return flat
+}
+
+// setBody stores a body in a fixture message, as JSON if it is valid JSON.
+func (m *FixtureMessage) setBody(body []byte, token string) {
+	redacted := redact(string(body), token)
+	if len(body) > 0 && json.Valid([]byte(redacted)) {
+		m.Body = json.RawMessage(redacted)
+	} else {
+		m.Text = redacted
+	}
+}
+
+// body returns the recorded body.
+func (m *FixtureMessage) body() []byte {
+	if m.Body != nil {
+		return m.Body
+	}
+	return []byte(m.Text)
+}
+
+// readRequestBody reads a request body without consuming it.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	if req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return body, nil
+	}
+	reader, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	return io.ReadAll(reader)
+}
+
+// RecordTransport is an HTTP transport that saves every request and response
+// as a numbered fixture file, with the bearer token and other secrets redacted.
+type RecordTransport struct {
+	Dir string
+	// Token returns the API token to redact, read only when first needed
+	Token func() string
+	Next  http.RoundTripper
+
+	mu    sync.Mutex
+	count int
+}
+
+// NewRecordTransport creates a transport that records to a directory.
+// Previous fixture files in the directory are removed.
+//   - dir: the fixtures directory
+//   - token: returns the API token to redact
+//   - next: the transport that makes the actual requests
+//
+// Returns a pointer to a RecordTransport and an error, if any.
+func NewRecordTransport(dir string, token func() string, next http.RoundTripper) (*RecordTransport, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	names, err := fixtureNames(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return nil, err
+		}
+	}
+	return &RecordTransport{Dir: dir, Token: token, Next: next}, nil
+}
+
+// RoundTrip makes the request with the next transport and records the exchange.
+func (t *RecordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	token := t.Token()
+	fixture := Fixture{
+		Request: FixtureMessage{
+			Method: req.Method,
+			Path:   req.URL.RequestURI(),
+			Header: fixtureHeader(req.Header, token),
+		},
+		Response: FixtureMessage{
+			Status: resp.StatusCode,
+			Header: fixtureHeader(resp.Header, token),
+		},
+	}
+	fixture.Request.setBody(reqBody, token)
+	fixture.Response.setBody(respBody, token)
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(fixture); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.count++
+	path := filepath.Join(t.Dir, fmt.Sprintf("%04d.json", t.count))
+	t.mu.Unlock()
+
+	if err := os.WriteFile(path, data.Bytes(), 0600); err != nil {
+		Warn("Failed to write fixture", err)
+	}
+	return resp, nil
+}
+
+// ReplayTransport is an HTTP transport that answers requests from recorded
+// fixture files, in order, without making any network access.
+type ReplayTransport struct {
+	mu       sync.Mutex
+	fixtures []Fixture
+	next     int
+}
+
+// NewReplayTransport loads the fixtures recorded in a directory.
+//   - dir: the fixtures directory, as written by RecordTransport
+//
+// Returns a pointer to a ReplayTransport and an error, if any.
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	names, err := fixtureNames(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no fixtures found in %s", dir)
+	}
+	t := &ReplayTransport{fixtures: make([]Fixture, len(names))}
+	for i, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &t.fixtures[i]); err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %w", name, err)
+		}
+	}
+	return t, nil
+}
+
+// RoundTrip returns the next recorded response, checking that the request matches.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.next >= len(t.fixtures) {
+		return nil, &replayError{fmt.Sprintf("replay: no recorded response for %s %s", req.Method, req.URL.RequestURI())}
+	}
+	fixture := t.fixtures[t.next]
+	if fixture.Request.Method != req.Method || fixture.Request.Path != req.URL.RequestURI() {
+		return nil, &replayError{fmt.Sprintf("replay: expected %s %s, got %s %s",
+			fixture.Request.Method, fixture.Request.Path, req.Method, req.URL.RequestURI())}
+	}
+	t.next++
+
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	// Sync commands get fresh uuids and temp_ids on every run, so map
+	// the recorded ones in the response to those of the live request.
+	respBody := string(fixture.Response.body())
+	recorded, live := commandIDs(fixture.Request.body()), commandIDs(reqBody)
+	for i := 0; i < len(recorded) && i < len(live); i++ {
+		respBody = strings.ReplaceAll(respBody, recorded[i], live[i])
+	}
+
+	header := make(http.Header)
+	for name, value := range fixture.Response.Header {
+		header.Set(name, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Response.Status, http.StatusText(fixture.Response.Status)),
+		StatusCode:    fixture.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+// commandIDs extracts the uuids and temp_ids of the Sync commands in a form-encoded body.
+// Returns the IDs in command order, or nil if the body has no commands.
+func commandIDs(body []byte) []string {
+	form, err := url.ParseQuery(string(body))
+	if err != nil || form.Get("commands") == "" {
+		return nil
+	}
+	var commands []SyncCommand
+	if err := json.Unmarshal([]byte(form.Get("commands")), &commands); err != nil {
+		return nil
+	}
+	ids := make([]string, 0)
+	for _, command := range commands {
+		ids = append(ids, command.UUID)
+		if command.TempID != "" {
+			ids = append(ids, command.TempID)
+		}
+	}
+	return ids
+}
+
+// fixtureNames returns the sorted names of the fixture files in a directory.
+func fixtureNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() && fixtureNameRegex.MatchString(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// isReplayError returns true if err comes from a replay mismatch.
+func isReplayError(err error) bool {
+	var replayErr *replayError
+	return errors.As(err, &replayErr)
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"time"
+)
+
+// Reminder types.
+const (
+	ReminderAbsolute = "absolute"
+	ReminderRelative = "relative"
+	ReminderLocation = "location"
+)
+
+// ReminderAddArgs returns the reminder_add command arguments for a reminder of a task.
+//   - itemID: the task ID, or the temp_id of a task created offline
+//   - at: when an absolute reminder fires, with a time of day or as a natural language
+//     string; nil for a relative reminder
+//   - minuteOffset: how many minutes before the task is due a relative reminder fires
+//
+// Returns the command arguments.
+func ReminderAddArgs(itemID string, at *DateParams, minuteOffset int) map[string]interface{} {
+	args := map[string]interface{}{"item_id": itemID}
+	if at == nil {
+		args["type"] = ReminderRelative
+		args["minute_offset"] = minuteOffset
+		return args
+	}
+	args["type"] = ReminderAbsolute
+	due := make(map[string]interface{})
+	if at.DueDateTime != "" {
+		due["date"] = at.DueDateTime
+	} else {
+		due["string"] = at.DueString
+		due["lang"] = at.DueLang
+	}
+	args["due"] = due
+	return args
+}
+
+// DueTime returns the time of day a due date is set to, in local time. Floating due
+// dates, without a timezone, are taken as local.
+//   - due: the due date of a task or reminder
+//
+// Returns the time, and false if there is no due date or it has no time of day.
+func DueTime(due *Due) (time.Time, bool) {
+	if due == nil {
+		return time.Time{}, false
+	}
+	for _, value := range []string{due.Datetime, due.Date} {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t.Local(), true
+		}
+		if t, err := time.ParseInLocation("2006-01-02T15:04:05", value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ReminderTime returns when a reminder fires.
+//   - reminder: the reminder
+//   - item: the task of the reminder
+//
+// Returns the due time of an absolute reminder, or the due time of the task less the
+// minute offset of a relative one, and false if unknown, e.g., for location reminders,
+// or relative reminders of tasks without a due time.
+func ReminderTime(reminder TodoistReminder, item TodoistItem) (time.Time, bool) {
+	switch reminder.Type {
+	case ReminderAbsolute:
+		return DueTime(reminder.Due)
+	case ReminderRelative:
+		if due, ok := DueTime(item.Due); ok {
+			return due.Add(-time.Duration(reminder.MinuteOffset) * time.Minute), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Default values for the retry policy.
+const (
+	DefaultMaxAttempts    = 4
+	DefaultInitialBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff     = 30 * time.Second
+)
+
+// newRetryPolicy fills in the defaults for unset retry settings.
+func newRetryPolicy(config Retry) Retry {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultInitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	return config
+}
+
+// isRetryableStatus returns true for the HTTP status codes worth retrying:
+// rate limiting and transient server errors.
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before the next attempt.
+//   - attempt: the number of the attempt that just failed, starting at 1
+//
+// The delay doubles on every attempt up to MaxBackoff, with random jitter
+// between half and the full delay so that concurrent clients spread out.
+func (r Retry) backoff(attempt int) time.Duration {
+	delay := r.InitialBackoff
+	for i := 1; i < attempt && delay < r.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.MaxBackoff {
+		delay = r.MaxBackoff
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// parseRetryAfter parses a Retry-After header, either in seconds or as an HTTP date.
+// Returns the delay and true, or false if the header is absent or invalid.
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if delay := t.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
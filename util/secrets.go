@@ -0,0 +1,198 @@
+// Copyright 2025 Marco Bravo Mejías. All rights reserved.
+// Use of this source code is governed by a GPL v3 license
+// that can be found in the LICENSE file.
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Credential stores, as set by the token_store configuration key.
+const (
+	StoreFile          = "file"
+	StoreSecretService = "secret-service"
+)
+
+// CredentialsFileName is the name of the credentials file of the file store.
+const CredentialsFileName = "credentials.json"
+
+// CredentialStore keeps the credentials saved by `todoister auth login`.
+type CredentialStore interface {
+	// Load returns the saved credentials, or nil and no error if there are none.
+	Load() (*Credentials, error)
+	// Save replaces the saved credentials.
+	Save(credentials *Credentials) error
+	// Remove deletes the saved credentials and returns true if there were any.
+	Remove() (bool, error)
+	// Location describes where the credentials are kept.
+	Location() string
+}
+
+// NewCredentialStore returns the credential store for a token_store setting.
+//   - name: StoreFile (the default if empty) or StoreSecretService
+//
+// Returns the CredentialStore and an error if the name is unknown.
+func NewCredentialStore(name string) (CredentialStore, error) {
+	switch name {
+	case "", StoreFile:
+		configDir, err := ConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		return &fileStore{path: filepath.Join(configDir, CredentialsFileName)}, nil
+	case StoreSecretService:
+		return &secretServiceStore{}, nil
+	}
+	return nil, fmt.Errorf("unknown token_store '%s', expected '%s' or '%s'", name, StoreFile, StoreSecretService)
+}
+
+// fileStore keeps credentials in a JSON file next to the configuration file,
+// readable only by the user.
+type fileStore struct {
+	path string
+}
+
+func (s *fileStore) Load() (*Credentials, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	credentials := &Credentials{}
+	if err := json.Unmarshal(data, credentials); err != nil {
+		return nil, fmt.Errorf("invalid credentials file %s: %w", s.path, err)
+	}
+	return credentials, nil
+}
+
+func (s *fileStore) Save(credentials *Credentials) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(credentials, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, append(data, '\n'), 0600)
+}
+
+func (s *fileStore) Remove() (bool, error) {
+	err := os.Remove(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *fileStore) Location() string {
+	return s.path
+}
+
+// secretServiceStore keeps credentials in the freedesktop Secret Service
+// (GNOME Keyring, KWallet, KeePassXC…) through the secret-tool command of libsecret.
+type secretServiceStore struct{}
+
+// secretAttributes identify Todoister's credentials in the Secret Service.
+var secretAttributes = []string{"service", Prog, "type", "credentials"}
+
+// secretTool runs secret-tool with the given arguments and input.
+// Returns its standard output and an error, if any.
+func secretTool(input string, args ...string) (string, error) {
+	cmd := exec.Command("secret-tool", append(args, secretAttributes...)...)
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("the Secret Service store needs secret-tool (usually in package libsecret-tools): %w", err)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() == 0 {
+			// secret-tool exits with 1 and no message when nothing matches
+			return "", nil
+		}
+		return "", fmt.Errorf("secret-tool failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+func (s *secretServiceStore) Load() (*Credentials, error) {
+	secret, err := secretTool("", "lookup")
+	if err != nil || secret == "" {
+		return nil, err
+	}
+	credentials := &Credentials{}
+	if err := json.Unmarshal([]byte(secret), credentials); err != nil {
+		return nil, fmt.Errorf("invalid credentials in the Secret Service: %w", err)
+	}
+	return credentials, nil
+}
+
+func (s *secretServiceStore) Save(credentials *Credentials) error {
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	_, err = secretTool(string(data), "store", "--label", fmt.Sprintf("%s Todoist token", Prog))
+	return err
+}
+
+func (s *secretServiceStore) Remove() (bool, error) {
+	credentials, err := s.Load()
+	if err != nil || credentials == nil {
+		return false, err
+	}
+	_, err = secretTool("", "clear")
+	return err == nil, err
+}
+
+func (s *secretServiceStore) Location() string {
+	return "the Secret Service"
+}
+
+// RunTokenCommand runs the token_command setting with the shell and reads the token
+// from its standard output, e.g., "pass show todoist". Only the first line is used,
+// as password managers often keep metadata on the following ones.
+// Returns the token and an error if the command fails or prints nothing.
+func RunTokenCommand(command string) (string, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = os.Stdin   // Let the command ask for a passphrase
+	cmd.Stderr = os.Stderr // and show its prompts and errors
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("token_command failed: %w", err)
+	}
+	token, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("token_command printed no token")
+	}
+	return token, nil
+}
+
+// CheckConfigPermissions checks that a configuration file holding secrets
+// cannot be read by other users.
+//   - path: the configuration file
+//
+// Returns an error describing the problem, or nil if the file is private.
+func CheckConfigPermissions(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0o004 != 0 {
+		return fmt.Errorf("%s contains a secret and is readable by all users; run 'chmod 600 %s' "+
+			"or move the secret to token_command or cache.key_command", path, path)
+	}
+	return nil
+}
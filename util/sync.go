@@ -5,6 +5,8 @@
 package util
 
 import (
+	"maps"
+	"slices"
 	"time"
 )
 
@@ -16,17 +18,28 @@ func convertCachedToTodoistData(cached *CachedTodoistData) *TodoistData {
 		Items:    make([]TodoistItem, len(cached.Items)),
 		Labels:   make([]TodoistLabel, len(cached.Labels)),
 		Comments: make([]TodoistComment, len(cached.Comments)),
+
+		Reminders:          make([]TodoistReminder, len(cached.Reminders)),
+		Collaborators:      make([]TodoistCollaborator, len(cached.Collaborators)),
+		CollaboratorStates: make([]TodoistCollaboratorState, len(cached.CollaboratorStates)),
 	}
 
 	// Convert Projects
 	for i, p := range cached.Projects {
 		todoistData.Projects[i] = TodoistProject{
-			ID:       p.GetId(),
-			ParentID: p.GetParentId(),
+			ID:         p.GetId(),
+			ParentID:   p.GetParentId(),
+			ChildOrder: int(p.GetChildOrder()),
 			Project: Project{
-				Name:      p.GetName(),
-				Color:     p.GetColor(),
-				ViewStyle: p.GetViewStyle(),
+				Name:         p.GetName(),
+				Color:        p.GetColor(),
+				ViewStyle:    p.GetViewStyle(),
+				CreatedAt:    p.GetCreatedAt(),
+				UpdatedAt:    p.GetUpdatedAt(),
+				IsFavorite:   p.GetIsFavorite(),
+				IsArchived:   p.GetIsArchived(),
+				IsShared:     p.GetIsShared(),
+				InboxProject: p.GetInboxProject(),
 			},
 		}
 	}
@@ -46,41 +59,7 @@ func convertCachedToTodoistData(cached *CachedTodoistData) *TodoistData {
 
 	// Convert Items
 	for i, item := range cached.Items {
-		todoistItem := TodoistItem{
-			ID:        item.GetId(),
-			ProjectID: item.GetProjectId(),
-			SectionID: item.GetSectionId(),
-			Labels:    item.GetLabels(),
-			Task: Task{
-				Content:     item.GetContent(),
-				Description: item.GetDescription(),
-				Priority:    int(item.GetPriority()),
-				ChildOrder:  int(item.GetChildOrder()),
-				Collapsed:   item.GetCollapsed(),
-				CompletedAt: item.GetCompletedAt(),
-			},
-		}
-
-		// Convert Duration if present
-		if item.Duration != nil {
-			todoistItem.Duration = &Duration{
-				Amount: int(item.Duration.GetAmount()),
-				Unit:   item.Duration.GetUnit(),
-			}
-		}
-
-		// Convert Due if present
-		if item.Due != nil {
-			todoistItem.Due = &Due{
-				Date:        item.Due.GetDate(),
-				IsRecurring: item.Due.GetIsRecurring(),
-				String:      item.Due.GetDueString(),
-				Datetime:    item.Due.GetDatetime(),
-				Timezone:    item.Due.GetTimezone(),
-			}
-		}
-
-		todoistData.Items[i] = todoistItem
+		todoistData.Items[i] = itemFromPb(item)
 	}
 
 	// Convert Labels
@@ -101,34 +80,225 @@ func convertCachedToTodoistData(cached *CachedTodoistData) *TodoistData {
 			TaskID:    c.GetTaskId(),
 			ProjectID: c.GetProjectId(),
 			Comment: Comment{
-				Content: c.GetContent(),
+				Content:   c.GetContent(),
+				PostedAt:  c.GetPostedAt(),
+				PostedUID: c.GetPostedUid(),
 			},
 		}
 	}
 
+	// Convert Reminders
+	for i, r := range cached.Reminders {
+		todoistData.Reminders[i] = TodoistReminder{
+			ID:        r.GetId(),
+			ItemID:    r.GetItemId(),
+			NotifyUID: r.GetNotifyUid(),
+			Reminder: Reminder{
+				Type:         r.GetType(),
+				MinuteOffset: int(r.GetMinuteOffset()),
+			},
+		}
+		if r.Due != nil {
+			todoistData.Reminders[i].Due = &Due{
+				Date:        r.Due.GetDate(),
+				IsRecurring: r.Due.GetIsRecurring(),
+				String:      r.Due.GetDueString(),
+				Datetime:    r.Due.GetDatetime(),
+				Timezone:    r.Due.GetTimezone(),
+			}
+		}
+	}
+
+	// Convert User if present
+	if u := cached.User; u != nil {
+		todoistData.User = &TodoistUser{
+			ID:            u.GetId(),
+			Email:         u.GetEmail(),
+			FullName:      u.GetFullName(),
+			PremiumStatus: u.GetPremiumStatus(),
+			TzInfo: TodoistTimezone{
+				Timezone:  u.GetTimezone(),
+				GMTString: u.GetGmtString(),
+			},
+		}
+	}
+
+	// Convert Collaborators
+	for i, c := range cached.Collaborators {
+		todoistData.Collaborators[i] = TodoistCollaborator{
+			ID: c.GetId(),
+			Collaborator: Collaborator{
+				Email:    c.GetEmail(),
+				FullName: c.GetFullName(),
+				Timezone: c.GetTimezone(),
+			},
+		}
+	}
+
+	// Convert Collaborator States
+	for i, s := range cached.CollaboratorStates {
+		todoistData.CollaboratorStates[i] = TodoistCollaboratorState{
+			ProjectID: s.GetProjectId(),
+			UserID:    s.GetUserId(),
+			State:     s.GetState(),
+		}
+	}
+
+	if len(cached.WrittenThrough) > 0 {
+		todoistData.WrittenThrough = make(map[string]bool)
+		for _, id := range cached.WrittenThrough {
+			todoistData.WrittenThrough[id] = true
+		}
+	}
+
 	return todoistData
 }
 
+// itemFromPb converts a cached item to a TodoistItem.
+func itemFromPb(item *PbItem) TodoistItem {
+	todoistItem := TodoistItem{
+		ID:        item.GetId(),
+		ProjectID: item.GetProjectId(),
+		SectionID: item.GetSectionId(),
+		ParentID:  item.GetParentId(),
+		Labels:    item.GetLabels(),
+		Task: Task{
+			Content:        item.GetContent(),
+			Description:    item.GetDescription(),
+			Priority:       int(item.GetPriority()),
+			ChildOrder:     int(item.GetChildOrder()),
+			Collapsed:      item.GetCollapsed(),
+			AddedAt:        item.GetAddedAt(),
+			UpdatedAt:      item.GetUpdatedAt(),
+			CompletedAt:    item.GetCompletedAt(),
+			ResponsibleUID: item.GetResponsibleUid(),
+			AssignedByUID:  item.GetAssignedByUid(),
+			NoteCount:      int(item.GetNoteCount()),
+		},
+	}
+
+	// Convert Duration if present
+	if item.Duration != nil {
+		todoistItem.Duration = &Duration{
+			Amount: int(item.Duration.GetAmount()),
+			Unit:   item.Duration.GetUnit(),
+		}
+	}
+
+	// Convert Due if present
+	if item.Due != nil {
+		todoistItem.Due = &Due{
+			Date:        item.Due.GetDate(),
+			IsRecurring: item.Due.GetIsRecurring(),
+			String:      item.Due.GetDueString(),
+			Datetime:    item.Due.GetDatetime(),
+			Timezone:    item.Due.GetTimezone(),
+		}
+	}
+
+	// Convert Deadline if present
+	if item.Deadline != nil {
+		todoistItem.Deadline = &Deadline{
+			Date: item.Deadline.GetDate(),
+			Lang: item.Deadline.GetLang(),
+		}
+	}
+
+	return todoistItem
+}
+
+// itemToPb converts a TodoistItem to a cached item.
+func itemToPb(item TodoistItem) *PbItem {
+	cachedItem := &PbItem{
+		Id:             item.ID,
+		ProjectId:      item.ProjectID,
+		SectionId:      item.SectionID,
+		ParentId:       item.ParentID,
+		Content:        item.Content,
+		Description:    item.Description,
+		Priority:       int32(item.Priority),
+		ChildOrder:     int32(item.ChildOrder),
+		Collapsed:      item.Collapsed,
+		Labels:         item.Labels,
+		AddedAt:        item.AddedAt,
+		UpdatedAt:      item.UpdatedAt,
+		CompletedAt:    item.CompletedAt,
+		ResponsibleUid: item.ResponsibleUID,
+		AssignedByUid:  item.AssignedByUID,
+		NoteCount:      int32(item.NoteCount),
+	}
+
+	// Convert Duration if present
+	if item.Duration != nil {
+		cachedItem.Duration = &PbDuration{
+			Amount: int32(item.Duration.Amount),
+			Unit:   item.Duration.Unit,
+		}
+	}
+
+	// Convert Due if present
+	if item.Due != nil {
+		cachedItem.Due = &PbDue{
+			Date:        item.Due.Date,
+			IsRecurring: item.Due.IsRecurring,
+			DueString:   item.Due.String,
+			Datetime:    item.Due.Datetime,
+			Timezone:    item.Due.Timezone,
+		}
+	}
+
+	// Convert Deadline if present
+	if item.Deadline != nil {
+		cachedItem.Deadline = &PbDeadline{
+			Date: item.Deadline.Date,
+			Lang: item.Deadline.Lang,
+		}
+	}
+
+	return cachedItem
+}
+
+// cachedSyncTokens returns the sync token per resource type of a cache.
+// Caches written before per-resource tokens have a single token valid for all types.
+func cachedSyncTokens(cached *CachedTodoistData) map[string]string {
+	tokens := make(map[string]string)
+	for r, token := range cached.GetSyncTokens() {
+		tokens[r] = token
+	}
+	return tokens
+}
+
 // convertTodoistDataToCached converts TodoistData to CachedTodoistData protobuf message.
-func convertTodoistDataToCached(data *TodoistData, syncToken string) *CachedTodoistData {
+func convertTodoistDataToCached(data *TodoistData, syncTokens map[string]string) *CachedTodoistData {
 	cached := &CachedTodoistData{
-		SyncToken: syncToken,
-		CachedAt:  time.Now().Unix(),
-		Projects:  make([]*PbProject, len(data.Projects)),
-		Sections:  make([]*PbSection, len(data.Sections)),
-		Items:     make([]*PbItem, len(data.Items)),
-		Labels:    make([]*PbLabel, len(data.Labels)),
-		Comments:  make([]*PbComment, len(data.Comments)),
+		SyncTokens: syncTokens,
+		CachedAt:   time.Now().Unix(),
+		Projects:   make([]*PbProject, len(data.Projects)),
+		Sections:   make([]*PbSection, len(data.Sections)),
+		Items:      make([]*PbItem, len(data.Items)),
+		Labels:     make([]*PbLabel, len(data.Labels)),
+		Comments:   make([]*PbComment, len(data.Comments)),
+
+		Reminders:          make([]*PbReminder, len(data.Reminders)),
+		Collaborators:      make([]*PbCollaborator, len(data.Collaborators)),
+		CollaboratorStates: make([]*PbCollaboratorState, len(data.CollaboratorStates)),
 	}
 
 	// Convert Projects
 	for i, p := range data.Projects {
 		cached.Projects[i] = &PbProject{
-			Id:        p.ID,
-			ParentId:  p.ParentID,
-			Name:      p.Name,
-			Color:     p.Color,
-			ViewStyle: p.ViewStyle,
+			Id:           p.ID,
+			ParentId:     p.ParentID,
+			Name:         p.Name,
+			Color:        p.Color,
+			ViewStyle:    p.ViewStyle,
+			CreatedAt:    p.CreatedAt,
+			UpdatedAt:    p.UpdatedAt,
+			IsFavorite:   p.IsFavorite,
+			IsArchived:   p.IsArchived,
+			IsShared:     p.IsShared || p.Shared,
+			InboxProject: p.InboxProject,
+			ChildOrder:   int32(p.ChildOrder),
 		}
 	}
 
@@ -145,39 +315,7 @@ func convertTodoistDataToCached(data *TodoistData, syncToken string) *CachedTodo
 
 	// Convert Items
 	for i, item := range data.Items {
-		cachedItem := &PbItem{
-			Id:          item.ID,
-			ProjectId:   item.ProjectID,
-			SectionId:   item.SectionID,
-			Content:     item.Content,
-			Description: item.Description,
-			Priority:    int32(item.Priority),
-			ChildOrder:  int32(item.ChildOrder),
-			Collapsed:   item.Collapsed,
-			Labels:      item.Labels,
-			CompletedAt: item.CompletedAt,
-		}
-
-		// Convert Duration if present
-		if item.Duration != nil {
-			cachedItem.Duration = &PbDuration{
-				Amount: int32(item.Duration.Amount),
-				Unit:   item.Duration.Unit,
-			}
-		}
-
-		// Convert Due if present
-		if item.Due != nil {
-			cachedItem.Due = &PbDue{
-				Date:        item.Due.Date,
-				IsRecurring: item.Due.IsRecurring,
-				DueString:   item.Due.String,
-				Datetime:    item.Due.Datetime,
-				Timezone:    item.Due.Timezone,
-			}
-		}
-
-		cached.Items[i] = cachedItem
+		cached.Items[i] = itemToPb(item)
 	}
 
 	// Convert Labels
@@ -196,147 +334,416 @@ func convertTodoistDataToCached(data *TodoistData, syncToken string) *CachedTodo
 			TaskId:    c.TaskID,
 			ProjectId: c.ProjectID,
 			Content:   c.Content,
+			PostedAt:  c.PostedAt,
+			PostedUid: c.PostedUID,
 		}
 	}
 
-	return cached
-}
+	// Convert Reminders
+	for i, r := range data.Reminders {
+		cached.Reminders[i] = &PbReminder{
+			Id:           r.ID,
+			ItemId:       r.ItemID,
+			NotifyUid:    r.NotifyUID,
+			Type:         r.Type,
+			MinuteOffset: int32(r.MinuteOffset),
+		}
+		if r.Due != nil {
+			cached.Reminders[i].Due = &PbDue{
+				Date:        r.Due.Date,
+				IsRecurring: r.Due.IsRecurring,
+				DueString:   r.Due.String,
+				Datetime:    r.Due.Datetime,
+				Timezone:    r.Due.Timezone,
+			}
+		}
+	}
 
-// mergeData merges incremental sync data into existing cached data.
-// Handles additions, updates, and deletions (via is_deleted flag).
-func mergeData(cached *TodoistData, incremental *SyncResponse) *TodoistData {
-	// Create maps for O(1) lookups
-	projectMap := make(map[string]TodoistProject)
-	sectionMap := make(map[string]TodoistSection)
-	itemMap := make(map[string]TodoistItem)
-	labelMap := make(map[string]TodoistLabel)
-	commentMap := make(map[string]TodoistComment)
+	// Convert User if present
+	if u := data.User; u != nil {
+		cached.User = &PbUser{
+			Id:            u.ID,
+			Email:         u.Email,
+			FullName:      u.FullName,
+			Timezone:      u.TzInfo.Timezone,
+			GmtString:     u.TzInfo.GMTString,
+			PremiumStatus: u.PremiumStatus,
+		}
+	}
 
-	// Populate maps with cached data
-	for _, p := range cached.Projects {
-		projectMap[p.ID] = p
+	// Convert Collaborators
+	for i, c := range data.Collaborators {
+		cached.Collaborators[i] = &PbCollaborator{
+			Id:       c.ID,
+			Email:    c.Email,
+			FullName: c.FullName,
+			Timezone: c.Timezone,
+		}
 	}
-	for _, s := range cached.Sections {
-		sectionMap[s.ID] = s
+
+	// Convert Collaborator States
+	for i, s := range data.CollaboratorStates {
+		cached.CollaboratorStates[i] = &PbCollaboratorState{
+			ProjectId: s.ProjectID,
+			UserId:    s.UserID,
+			State:     s.State,
+		}
 	}
-	for _, i := range cached.Items {
-		itemMap[i.ID] = i
+
+	for id := range data.WrittenThrough {
+		cached.WrittenThrough = append(cached.WrittenThrough, id)
 	}
-	for _, l := range cached.Labels {
-		labelMap[l.ID] = l
+	slices.Sort(cached.WrittenThrough)
+
+	return cached
+}
+
+// mergeResources applies the synced resources of one type to the cached ones.
+//   - cached: the cached resources
+//   - synced: the resources returned by the Sync API
+//   - fullSync: true if synced replaces cached entirely
+//   - id: returns the ID of a resource
+//   - isDeleted: returns true if a resource was deleted
+//
+// Returns the merged resources, with updates in place and additions appended in
+// API order, and the set of IDs removed by the sync.
+func mergeResources[T any](cached, synced []T, fullSync bool, id func(T) string, isDeleted func(T) bool) ([]T, map[string]bool) {
+	latest := make(map[string]T, len(synced))
+	order := make([]string, 0, len(synced))
+	for _, r := range synced {
+		if _, seen := latest[id(r)]; !seen {
+			order = append(order, id(r))
+		}
+		latest[id(r)] = r
+	}
+
+	removed := make(map[string]bool)
+	merged := make([]T, 0, len(cached)+len(synced))
+	for _, r := range cached {
+		update, ok := latest[id(r)]
+		switch {
+		case !ok && fullSync:
+			// Missing from a full sync, so it no longer exists
+			removed[id(r)] = true
+		case !ok:
+			merged = append(merged, r)
+		case isDeleted(update):
+			removed[id(r)] = true
+			delete(latest, id(r))
+		default:
+			merged = append(merged, update)
+			delete(latest, id(r))
+		}
 	}
-	for _, c := range cached.Comments {
-		commentMap[c.ID] = c
+	for _, rid := range order {
+		if r, ok := latest[rid]; ok && !isDeleted(r) {
+			merged = append(merged, r)
+		}
 	}
+	return merged, removed
+}
 
-	// Merge Projects (updates and additions, filter out deletions)
-	for _, p := range incremental.Projects {
-		if p.IsDeleted {
-			delete(projectMap, p.ID)
-		} else {
-			projectMap[p.ID] = p
-		}
+// mergeData merges sync data into existing cached data.
+//   - cached: the cached data
+//   - incremental: the Sync API response
+//   - resourceTypes: the resource types requested; others are left untouched
+//
+// Returns the merged data. Handles additions, updates and deletions (via the is_deleted
+// flag, or absence from a full sync). Deletions cascade to the sections, tasks, comments and
+// collaborator states of deleted projects, and to the subtasks, comments and reminders of
+// deleted tasks, even if those resource types were not synced.
+func mergeData(cached *TodoistData, incremental *SyncResponse, resourceTypes []string) *TodoistData {
+	synced := make(map[string]bool)
+	for _, r := range resourceTypes {
+		synced[r] = true
+	}
+	full := incremental.FullSync
+	result := *cached
+
+	// Merge Projects
+	removedProjects := make(map[string]bool)
+	if synced[ResourceProjects] {
+		result.Projects, removedProjects = mergeResources(cached.Projects, incremental.Projects, full,
+			func(p TodoistProject) string { return p.ID },
+			func(p TodoistProject) bool { return p.IsDeleted })
 	}
 
 	// Merge Sections
-	for _, s := range incremental.Sections {
-		if s.IsDeleted {
-			delete(sectionMap, s.ID)
-		} else {
-			sectionMap[s.ID] = s
-		}
+	removedSections := make(map[string]bool)
+	if synced[ResourceSections] {
+		result.Sections, removedSections = mergeResources(cached.Sections, incremental.Sections, full,
+			func(s TodoistSection) string { return s.ID },
+			func(s TodoistSection) bool { return s.IsDeleted })
 	}
 
 	// Merge Items
-	for _, i := range incremental.Items {
-		if i.IsDeleted {
-			delete(itemMap, i.ID)
-		} else {
-			itemMap[i.ID] = i
-		}
+	removedItems := make(map[string]bool)
+	if synced[ResourceItems] {
+		result.Items, removedItems = mergeResources(cached.Items, incremental.Items, full,
+			func(i TodoistItem) string { return i.ID },
+			func(i TodoistItem) bool { return i.IsDeleted })
 	}
 
 	// Merge Labels
-	for _, l := range incremental.Labels {
-		if l.IsDeleted {
-			delete(labelMap, l.ID)
-		} else {
-			labelMap[l.ID] = l
-		}
+	if synced[ResourceLabels] {
+		result.Labels, _ = mergeResources(cached.Labels, incremental.Labels, full,
+			func(l TodoistLabel) string { return l.ID },
+			func(l TodoistLabel) bool { return l.IsDeleted })
 	}
 
-	// Merge Comments (both notes and project_notes)
-	for _, c := range incremental.Notes {
-		if c.IsDeleted {
-			delete(commentMap, c.ID)
+	// Merge Comments: notes belong to tasks, project_notes to projects
+	var taskComments, projectComments []TodoistComment
+	for _, c := range cached.Comments {
+		if c.TaskID != "" {
+			taskComments = append(taskComments, c)
 		} else {
-			commentMap[c.ID] = c
+			projectComments = append(projectComments, c)
 		}
 	}
-	for _, c := range incremental.ProjectNotes {
-		if c.IsDeleted {
-			delete(commentMap, c.ID)
-		} else {
-			commentMap[c.ID] = c
+	commentID := func(c TodoistComment) string { return c.ID }
+	commentDeleted := func(c TodoistComment) bool { return c.IsDeleted }
+	if synced[ResourceNotes] {
+		taskComments, _ = mergeResources(taskComments, incremental.Notes, full, commentID, commentDeleted)
+	}
+	if synced[ResourceProjectNotes] {
+		projectComments, _ = mergeResources(projectComments, incremental.ProjectNotes, full, commentID, commentDeleted)
+	}
+	result.Comments = append(taskComments, projectComments...)
+
+	// Merge Reminders
+	if synced[ResourceReminders] {
+		result.Reminders, _ = mergeResources(cached.Reminders, incremental.Reminders, full,
+			func(r TodoistReminder) string { return r.ID },
+			func(r TodoistReminder) bool { return r.IsDeleted })
+	}
+
+	// Resources the sync returns replace those written through, and those it
+	// deletes are gone
+	if len(cached.WrittenThrough) > 0 {
+		returned := make(map[string]bool)
+		if synced[ResourceProjects] {
+			for _, p := range incremental.Projects {
+				returned[p.ID] = true
+			}
+		}
+		if synced[ResourceItems] {
+			for _, i := range incremental.Items {
+				returned[i.ID] = true
+			}
+		}
+		result.WrittenThrough = make(map[string]bool)
+		for _, p := range result.Projects {
+			if cached.WrittenThrough[p.ID] && !returned[p.ID] {
+				result.WrittenThrough[p.ID] = true
+			}
+		}
+		for _, i := range result.Items {
+			if cached.WrittenThrough[i.ID] && !returned[i.ID] {
+				result.WrittenThrough[i.ID] = true
+			}
 		}
 	}
 
-	// Clean up orphaned items
+	// Merge the User, returned only if it changed
+	if synced[ResourceUser] && incremental.User != nil {
+		result.User = incremental.User
+	}
+
+	// Merge Collaborators: they are removed from projects through their states,
+	// and are never deleted themselves
+	if synced[ResourceCollaborators] {
+		result.Collaborators, _ = mergeResources(cached.Collaborators, incremental.Collaborators, full,
+			func(c TodoistCollaborator) string { return c.ID },
+			func(c TodoistCollaborator) bool { return false })
+	}
+
+	// Merge Collaborator States, one per project and collaborator
+	if synced[ResourceCollaboratorStates] {
+		result.CollaboratorStates, _ = mergeResources(cached.CollaboratorStates, incremental.CollaboratorStates, full,
+			func(s TodoistCollaboratorState) string { return s.ProjectID + "/" + s.UserID },
+			func(s TodoistCollaboratorState) bool { return s.IsDeleted || s.State == "deleted" })
+	}
+
+	// Cascade deletions
 	// Remove sections that belong to deleted projects
-	for id, s := range sectionMap {
-		if _, exists := projectMap[s.ProjectID]; !exists {
-			delete(sectionMap, id)
+	sections := make([]TodoistSection, 0, len(result.Sections))
+	for _, s := range result.Sections {
+		if removedProjects[s.ProjectID] {
+			removedSections[s.ID] = true
+		} else {
+			sections = append(sections, s)
 		}
 	}
+	result.Sections = sections
 
 	// Remove items that belong to deleted projects or sections
-	for id, i := range itemMap {
-		if _, exists := projectMap[i.ProjectID]; !exists {
-			delete(itemMap, id)
-		} else if i.SectionID != "" {
-			if _, exists := sectionMap[i.SectionID]; !exists {
-				delete(itemMap, id)
-			}
+	items := make([]TodoistItem, 0, len(result.Items))
+	for _, i := range result.Items {
+		if removedProjects[i.ProjectID] || (i.SectionID != "" && removedSections[i.SectionID]) {
+			removedItems[i.ID] = true
+		} else {
+			items = append(items, i)
 		}
 	}
 
-	// Remove comments that belong to deleted items or projects
-	for id, c := range commentMap {
-		if c.TaskID != "" {
-			if _, exists := itemMap[c.TaskID]; !exists {
-				delete(commentMap, id)
-			}
-		} else if c.ProjectID != "" {
-			if _, exists := projectMap[c.ProjectID]; !exists {
-				delete(commentMap, id)
+	// Remove subtasks of deleted items, down to any depth
+	for changed := true; changed; {
+		changed = false
+		remaining := make([]TodoistItem, 0, len(items))
+		for _, i := range items {
+			if i.ParentID != "" && removedItems[i.ParentID] {
+				removedItems[i.ID] = true
+				changed = true
+			} else {
+				remaining = append(remaining, i)
 			}
 		}
+		items = remaining
 	}
+	result.Items = items
 
-	// Convert maps back to slices
-	result := &TodoistData{
-		Projects: make([]TodoistProject, 0, len(projectMap)),
-		Sections: make([]TodoistSection, 0, len(sectionMap)),
-		Items:    make([]TodoistItem, 0, len(itemMap)),
-		Labels:   make([]TodoistLabel, 0, len(labelMap)),
-		Comments: make([]TodoistComment, 0, len(commentMap)),
+	// Remove comments that belong to deleted items or projects
+	comments := make([]TodoistComment, 0, len(result.Comments))
+	for _, c := range result.Comments {
+		if (c.TaskID != "" && removedItems[c.TaskID]) || (c.TaskID == "" && removedProjects[c.ProjectID]) {
+			continue
+		}
+		comments = append(comments, c)
 	}
+	result.Comments = comments
 
-	for _, p := range projectMap {
-		result.Projects = append(result.Projects, p)
-	}
-	for _, s := range sectionMap {
-		result.Sections = append(result.Sections, s)
-	}
-	for _, i := range itemMap {
-		result.Items = append(result.Items, i)
-	}
-	for _, l := range labelMap {
-		result.Labels = append(result.Labels, l)
+	// Remove reminders of deleted items
+	reminders := make([]TodoistReminder, 0, len(result.Reminders))
+	for _, r := range result.Reminders {
+		if !removedItems[r.ItemID] {
+			reminders = append(reminders, r)
+		}
 	}
-	for _, c := range commentMap {
-		result.Comments = append(result.Comments, c)
+	result.Reminders = reminders
+
+	// Remove collaborator states of deleted projects
+	states := make([]TodoistCollaboratorState, 0, len(result.CollaboratorStates))
+	for _, s := range result.CollaboratorStates {
+		if !removedProjects[s.ProjectID] {
+			states = append(states, s)
+		}
 	}
+	result.CollaboratorStates = states
+
+	return &result
+}
 
+// applyCommand applies the changes a Sync command makes to data, as if they
+// were returned by a sync. Unsupported command types leave data unchanged.
+//   - data: the data the command applies to
+//   - command: the command
+//   - id: the ID of the resource the command creates, if any
+//   - at: when the command was made
+//
+// Returns the data with the changes applied.
+func applyCommand(data *TodoistData, command *SyncCommand, id string, at time.Time) *TodoistData {
+	args := command.Args
+	str := func(key string) string {
+		s, _ := args[key].(string)
+		return s
+	}
+
+	changes := &SyncResponse{}
+	var resourceType string
+	switch command.Type {
+	case "item_add":
+		item := TodoistItem{
+			ID:        id,
+			ProjectID: str("project_id"),
+			SectionID: str("section_id"),
+			ParentID:  str("parent_id"),
+			Labels:    []string{}, // As Todoist returns them
+			Task: Task{
+				Content:        str("content"),
+				Description:    str("description"),
+				Priority:       1, // Todoist's default
+				AddedAt:        at.UTC().Format(time.RFC3339),
+				ResponsibleUID: str("responsible_uid"),
+			},
+		}
+		if due, ok := args["due"].(map[string]interface{}); ok {
+			item.Due = &Due{}
+			item.Due.Date, _ = due["date"].(string)
+			item.Due.String, _ = due["string"].(string)
+		}
+		// Todoist adds tasks last among their siblings
+		for _, sibling := range data.Items {
+			if sibling.ProjectID == item.ProjectID && sibling.SectionID == item.SectionID && sibling.ParentID == item.ParentID {
+				item.ChildOrder = max(item.ChildOrder, sibling.ChildOrder+1)
+			}
+		}
+		changes.Items = []TodoistItem{item}
+		resourceType = ResourceItems
+	case "item_close":
+		for _, item := range data.Items {
+			// Todoist moves a recurring task to its next date instead, as the next sync shows
+			if item.ID == str("id") && (item.Due == nil || !item.Due.IsRecurring) {
+				item.CompletedAt = at.UTC().Format(time.RFC3339)
+				changes.Items = []TodoistItem{item}
+			}
+		}
+		resourceType = ResourceItems
+	case "item_delete":
+		changes.Items = []TodoistItem{{ID: str("id"), IsDeleted: true}}
+		resourceType = ResourceItems
+	case "reminder_add":
+		reminder := TodoistReminder{ID: id, ItemID: str("item_id")}
+		reminder.Type = str("type")
+		switch offset := args["minute_offset"].(type) {
+		case int:
+			reminder.MinuteOffset = offset
+		case float64: // As read back from the offline queue
+			reminder.MinuteOffset = int(offset)
+		}
+		if due, ok := args["due"].(map[string]interface{}); ok {
+			reminder.Due = &Due{}
+			reminder.Due.Date, _ = due["date"].(string)
+			reminder.Due.String, _ = due["string"].(string)
+		}
+		changes.Reminders = []TodoistReminder{reminder}
+		resourceType = ResourceReminders
+	case "reminder_delete":
+		changes.Reminders = []TodoistReminder{{ID: str("id"), IsDeleted: true}}
+		resourceType = ResourceReminders
+	case "project_add":
+		project := TodoistProject{ID: id, ParentID: str("parent_id")}
+		project.Name = str("name")
+		project.Color = str("color")
+		changes.Projects = []TodoistProject{project}
+		resourceType = ResourceProjects
+	case "project_delete":
+		// Deleting a project deletes its subprojects too
+		deleted := map[string]bool{str("id"): true}
+		for found := true; found; {
+			found = false
+			for _, p := range data.Projects {
+				if !deleted[p.ID] && deleted[p.ParentID] {
+					deleted[p.ID] = true
+					found = true
+				}
+			}
+		}
+		for id := range deleted {
+			changes.Projects = append(changes.Projects, TodoistProject{ID: id, IsDeleted: true})
+		}
+		resourceType = ResourceProjects
+	default:
+		return data
+	}
+	result := mergeData(data, changes, []string{resourceType})
+	// The resources written through stay so until a sync returns them
+	result.WrittenThrough = maps.Clone(data.WrittenThrough)
+	if command.Type == "item_add" || command.Type == "project_add" {
+		// Todoist fills in the rest of the new resource, which isn't a change to journal
+		if result.WrittenThrough == nil {
+			result.WrittenThrough = make(map[string]bool)
+		}
+		result.WrittenThrough[id] = true
+	}
 	return result
 }
@@ -155,6 +155,59 @@ func (x *PbDue) GetTimezone() string {
 	return ""
 }
 
+// PbDeadline represents a task's deadline in the cache
+type PbDeadline struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          string                 `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PbDeadline) Reset() {
+	*x = PbDeadline{}
+	mi := &file_util_todoist_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PbDeadline) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PbDeadline) ProtoMessage() {}
+
+func (x *PbDeadline) ProtoReflect() protoreflect.Message {
+	mi := &file_util_todoist_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PbDeadline.ProtoReflect.Descriptor instead.
+func (*PbDeadline) Descriptor() ([]byte, []int) {
+	return file_util_todoist_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PbDeadline) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *PbDeadline) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
 // PbProject represents a Todoist project in the cache
 type PbProject struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -163,13 +216,20 @@ type PbProject struct {
 	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
 	Color         string                 `protobuf:"bytes,4,opt,name=color,proto3" json:"color,omitempty"`
 	ViewStyle     string                 `protobuf:"bytes,5,opt,name=view_style,json=viewStyle,proto3" json:"view_style,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	IsFavorite    bool                   `protobuf:"varint,8,opt,name=is_favorite,json=isFavorite,proto3" json:"is_favorite,omitempty"`
+	IsArchived    bool                   `protobuf:"varint,9,opt,name=is_archived,json=isArchived,proto3" json:"is_archived,omitempty"`
+	IsShared      bool                   `protobuf:"varint,10,opt,name=is_shared,json=isShared,proto3" json:"is_shared,omitempty"`
+	InboxProject  bool                   `protobuf:"varint,11,opt,name=inbox_project,json=inboxProject,proto3" json:"inbox_project,omitempty"`
+	ChildOrder    int32                  `protobuf:"varint,12,opt,name=child_order,json=childOrder,proto3" json:"child_order,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PbProject) Reset() {
 	*x = PbProject{}
-	mi := &file_util_todoist_proto_msgTypes[2]
+	mi := &file_util_todoist_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -181,7 +241,7 @@ func (x *PbProject) String() string {
 func (*PbProject) ProtoMessage() {}
 
 func (x *PbProject) ProtoReflect() protoreflect.Message {
-	mi := &file_util_todoist_proto_msgTypes[2]
+	mi := &file_util_todoist_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -194,7 +254,7 @@ func (x *PbProject) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PbProject.ProtoReflect.Descriptor instead.
 func (*PbProject) Descriptor() ([]byte, []int) {
-	return file_util_todoist_proto_rawDescGZIP(), []int{2}
+	return file_util_todoist_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *PbProject) GetId() string {
@@ -232,6 +292,55 @@ func (x *PbProject) GetViewStyle() string {
 	return ""
 }
 
+func (x *PbProject) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *PbProject) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *PbProject) GetIsFavorite() bool {
+	if x != nil {
+		return x.IsFavorite
+	}
+	return false
+}
+
+func (x *PbProject) GetIsArchived() bool {
+	if x != nil {
+		return x.IsArchived
+	}
+	return false
+}
+
+func (x *PbProject) GetIsShared() bool {
+	if x != nil {
+		return x.IsShared
+	}
+	return false
+}
+
+func (x *PbProject) GetInboxProject() bool {
+	if x != nil {
+		return x.InboxProject
+	}
+	return false
+}
+
+func (x *PbProject) GetChildOrder() int32 {
+	if x != nil {
+		return x.ChildOrder
+	}
+	return 0
+}
+
 // PbSection represents a Todoist section in the cache
 type PbSection struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -246,7 +355,7 @@ type PbSection struct {
 
 func (x *PbSection) Reset() {
 	*x = PbSection{}
-	mi := &file_util_todoist_proto_msgTypes[3]
+	mi := &file_util_todoist_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -258,7 +367,7 @@ func (x *PbSection) String() string {
 func (*PbSection) ProtoMessage() {}
 
 func (x *PbSection) ProtoReflect() protoreflect.Message {
-	mi := &file_util_todoist_proto_msgTypes[3]
+	mi := &file_util_todoist_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -271,7 +380,7 @@ func (x *PbSection) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PbSection.ProtoReflect.Descriptor instead.
 func (*PbSection) Descriptor() ([]byte, []int) {
-	return file_util_todoist_proto_rawDescGZIP(), []int{3}
+	return file_util_todoist_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *PbSection) GetId() string {
@@ -311,26 +420,33 @@ func (x *PbSection) GetOrder() int32 {
 
 // PbItem represents a Todoist task/item in the cache
 type PbItem struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	ProjectId     string                 `protobuf:"bytes,2,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
-	SectionId     string                 `protobuf:"bytes,3,opt,name=section_id,json=sectionId,proto3" json:"section_id,omitempty"`
-	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
-	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
-	Priority      int32                  `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`
-	ChildOrder    int32                  `protobuf:"varint,7,opt,name=child_order,json=childOrder,proto3" json:"child_order,omitempty"`
-	Collapsed     bool                   `protobuf:"varint,8,opt,name=collapsed,proto3" json:"collapsed,omitempty"`
-	Labels        []string               `protobuf:"bytes,9,rep,name=labels,proto3" json:"labels,omitempty"`
-	Duration      *PbDuration            `protobuf:"bytes,10,opt,name=duration,proto3" json:"duration,omitempty"`
-	Due           *PbDue                 `protobuf:"bytes,11,opt,name=due,proto3" json:"due,omitempty"`
-	CompletedAt   string                 `protobuf:"bytes,12,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProjectId      string                 `protobuf:"bytes,2,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	SectionId      string                 `protobuf:"bytes,3,opt,name=section_id,json=sectionId,proto3" json:"section_id,omitempty"`
+	Content        string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	Description    string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	Priority       int32                  `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	ChildOrder     int32                  `protobuf:"varint,7,opt,name=child_order,json=childOrder,proto3" json:"child_order,omitempty"`
+	Collapsed      bool                   `protobuf:"varint,8,opt,name=collapsed,proto3" json:"collapsed,omitempty"`
+	Labels         []string               `protobuf:"bytes,9,rep,name=labels,proto3" json:"labels,omitempty"`
+	Duration       *PbDuration            `protobuf:"bytes,10,opt,name=duration,proto3" json:"duration,omitempty"`
+	Due            *PbDue                 `protobuf:"bytes,11,opt,name=due,proto3" json:"due,omitempty"`
+	CompletedAt    string                 `protobuf:"bytes,12,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	ParentId       string                 `protobuf:"bytes,13,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	AddedAt        string                 `protobuf:"bytes,14,opt,name=added_at,json=addedAt,proto3" json:"added_at,omitempty"`
+	UpdatedAt      string                 `protobuf:"bytes,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Deadline       *PbDeadline            `protobuf:"bytes,16,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	ResponsibleUid string                 `protobuf:"bytes,17,opt,name=responsible_uid,json=responsibleUid,proto3" json:"responsible_uid,omitempty"`
+	AssignedByUid  string                 `protobuf:"bytes,18,opt,name=assigned_by_uid,json=assignedByUid,proto3" json:"assigned_by_uid,omitempty"`
+	NoteCount      int32                  `protobuf:"varint,19,opt,name=note_count,json=noteCount,proto3" json:"note_count,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *PbItem) Reset() {
 	*x = PbItem{}
-	mi := &file_util_todoist_proto_msgTypes[4]
+	mi := &file_util_todoist_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -342,7 +458,7 @@ func (x *PbItem) String() string {
 func (*PbItem) ProtoMessage() {}
 
 func (x *PbItem) ProtoReflect() protoreflect.Message {
-	mi := &file_util_todoist_proto_msgTypes[4]
+	mi := &file_util_todoist_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -355,7 +471,7 @@ func (x *PbItem) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PbItem.ProtoReflect.Descriptor instead.
 func (*PbItem) Descriptor() ([]byte, []int) {
-	return file_util_todoist_proto_rawDescGZIP(), []int{4}
+	return file_util_todoist_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *PbItem) GetId() string {
@@ -442,6 +558,55 @@ func (x *PbItem) GetCompletedAt() string {
 	return ""
 }
 
+func (x *PbItem) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *PbItem) GetAddedAt() string {
+	if x != nil {
+		return x.AddedAt
+	}
+	return ""
+}
+
+func (x *PbItem) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *PbItem) GetDeadline() *PbDeadline {
+	if x != nil {
+		return x.Deadline
+	}
+	return nil
+}
+
+func (x *PbItem) GetResponsibleUid() string {
+	if x != nil {
+		return x.ResponsibleUid
+	}
+	return ""
+}
+
+func (x *PbItem) GetAssignedByUid() string {
+	if x != nil {
+		return x.AssignedByUid
+	}
+	return ""
+}
+
+func (x *PbItem) GetNoteCount() int32 {
+	if x != nil {
+		return x.NoteCount
+	}
+	return 0
+}
+
 // PbLabel represents a Todoist label in the cache
 type PbLabel struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -454,7 +619,7 @@ type PbLabel struct {
 
 func (x *PbLabel) Reset() {
 	*x = PbLabel{}
-	mi := &file_util_todoist_proto_msgTypes[5]
+	mi := &file_util_todoist_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -466,7 +631,7 @@ func (x *PbLabel) String() string {
 func (*PbLabel) ProtoMessage() {}
 
 func (x *PbLabel) ProtoReflect() protoreflect.Message {
-	mi := &file_util_todoist_proto_msgTypes[5]
+	mi := &file_util_todoist_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -479,7 +644,7 @@ func (x *PbLabel) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PbLabel.ProtoReflect.Descriptor instead.
 func (*PbLabel) Descriptor() ([]byte, []int) {
-	return file_util_todoist_proto_rawDescGZIP(), []int{5}
+	return file_util_todoist_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *PbLabel) GetId() string {
@@ -510,13 +675,15 @@ type PbComment struct {
 	TaskId        string                 `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
 	ProjectId     string                 `protobuf:"bytes,3,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
 	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	PostedAt      string                 `protobuf:"bytes,5,opt,name=posted_at,json=postedAt,proto3" json:"posted_at,omitempty"`
+	PostedUid     string                 `protobuf:"bytes,6,opt,name=posted_uid,json=postedUid,proto3" json:"posted_uid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PbComment) Reset() {
 	*x = PbComment{}
-	mi := &file_util_todoist_proto_msgTypes[6]
+	mi := &file_util_todoist_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -528,7 +695,7 @@ func (x *PbComment) String() string {
 func (*PbComment) ProtoMessage() {}
 
 func (x *PbComment) ProtoReflect() protoreflect.Message {
-	mi := &file_util_todoist_proto_msgTypes[6]
+	mi := &file_util_todoist_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -541,7 +708,7 @@ func (x *PbComment) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PbComment.ProtoReflect.Descriptor instead.
 func (*PbComment) Descriptor() ([]byte, []int) {
-	return file_util_todoist_proto_rawDescGZIP(), []int{6}
+	return file_util_todoist_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *PbComment) GetId() string {
@@ -572,23 +739,345 @@ func (x *PbComment) GetContent() string {
 	return ""
 }
 
-// CachedTodoistData is the main cache structure
-type CachedTodoistData struct {
+func (x *PbComment) GetPostedAt() string {
+	if x != nil {
+		return x.PostedAt
+	}
+	return ""
+}
+
+func (x *PbComment) GetPostedUid() string {
+	if x != nil {
+		return x.PostedUid
+	}
+	return ""
+}
+
+// PbReminder represents a Todoist reminder of a task in the cache
+type PbReminder struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SyncToken     string                 `protobuf:"bytes,1,opt,name=sync_token,json=syncToken,proto3" json:"sync_token,omitempty"`
-	CachedAt      int64                  `protobuf:"varint,2,opt,name=cached_at,json=cachedAt,proto3" json:"cached_at,omitempty"`
-	Projects      []*PbProject           `protobuf:"bytes,3,rep,name=projects,proto3" json:"projects,omitempty"`
-	Sections      []*PbSection           `protobuf:"bytes,4,rep,name=sections,proto3" json:"sections,omitempty"`
-	Items         []*PbItem              `protobuf:"bytes,5,rep,name=items,proto3" json:"items,omitempty"`
-	Labels        []*PbLabel             `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty"`
-	Comments      []*PbComment           `protobuf:"bytes,7,rep,name=comments,proto3" json:"comments,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ItemId        string                 `protobuf:"bytes,2,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	NotifyUid     string                 `protobuf:"bytes,3,opt,name=notify_uid,json=notifyUid,proto3" json:"notify_uid,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Due           *PbDue                 `protobuf:"bytes,5,opt,name=due,proto3" json:"due,omitempty"`
+	MinuteOffset  int32                  `protobuf:"varint,6,opt,name=minute_offset,json=minuteOffset,proto3" json:"minute_offset,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *PbReminder) Reset() {
+	*x = PbReminder{}
+	mi := &file_util_todoist_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PbReminder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PbReminder) ProtoMessage() {}
+
+func (x *PbReminder) ProtoReflect() protoreflect.Message {
+	mi := &file_util_todoist_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PbReminder.ProtoReflect.Descriptor instead.
+func (*PbReminder) Descriptor() ([]byte, []int) {
+	return file_util_todoist_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PbReminder) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PbReminder) GetItemId() string {
+	if x != nil {
+		return x.ItemId
+	}
+	return ""
+}
+
+func (x *PbReminder) GetNotifyUid() string {
+	if x != nil {
+		return x.NotifyUid
+	}
+	return ""
+}
+
+func (x *PbReminder) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *PbReminder) GetDue() *PbDue {
+	if x != nil {
+		return x.Due
+	}
+	return nil
+}
+
+func (x *PbReminder) GetMinuteOffset() int32 {
+	if x != nil {
+		return x.MinuteOffset
+	}
+	return 0
+}
+
+// PbUser represents the Todoist user in the cache
+type PbUser struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	FullName      string                 `protobuf:"bytes,3,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	Timezone      string                 `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	GmtString     string                 `protobuf:"bytes,5,opt,name=gmt_string,json=gmtString,proto3" json:"gmt_string,omitempty"`
+	PremiumStatus string                 `protobuf:"bytes,6,opt,name=premium_status,json=premiumStatus,proto3" json:"premium_status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PbUser) Reset() {
+	*x = PbUser{}
+	mi := &file_util_todoist_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PbUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PbUser) ProtoMessage() {}
+
+func (x *PbUser) ProtoReflect() protoreflect.Message {
+	mi := &file_util_todoist_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PbUser.ProtoReflect.Descriptor instead.
+func (*PbUser) Descriptor() ([]byte, []int) {
+	return file_util_todoist_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PbUser) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PbUser) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *PbUser) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *PbUser) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *PbUser) GetGmtString() string {
+	if x != nil {
+		return x.GmtString
+	}
+	return ""
+}
+
+func (x *PbUser) GetPremiumStatus() string {
+	if x != nil {
+		return x.PremiumStatus
+	}
+	return ""
+}
+
+// PbCollaborator represents a collaborator in shared projects in the cache
+type PbCollaborator struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	FullName      string                 `protobuf:"bytes,3,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	Timezone      string                 `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PbCollaborator) Reset() {
+	*x = PbCollaborator{}
+	mi := &file_util_todoist_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PbCollaborator) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PbCollaborator) ProtoMessage() {}
+
+func (x *PbCollaborator) ProtoReflect() protoreflect.Message {
+	mi := &file_util_todoist_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PbCollaborator.ProtoReflect.Descriptor instead.
+func (*PbCollaborator) Descriptor() ([]byte, []int) {
+	return file_util_todoist_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PbCollaborator) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PbCollaborator) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *PbCollaborator) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *PbCollaborator) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+// PbCollaboratorState represents the membership of a collaborator in a shared project in the cache
+type PbCollaboratorState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	State         string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PbCollaboratorState) Reset() {
+	*x = PbCollaboratorState{}
+	mi := &file_util_todoist_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PbCollaboratorState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PbCollaboratorState) ProtoMessage() {}
+
+func (x *PbCollaboratorState) ProtoReflect() protoreflect.Message {
+	mi := &file_util_todoist_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PbCollaboratorState.ProtoReflect.Descriptor instead.
+func (*PbCollaboratorState) Descriptor() ([]byte, []int) {
+	return file_util_todoist_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PbCollaboratorState) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *PbCollaboratorState) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *PbCollaboratorState) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+// CachedTodoistData is the main cache structure
+type CachedTodoistData struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	SyncToken          string                 `protobuf:"bytes,1,opt,name=sync_token,json=syncToken,proto3" json:"sync_token,omitempty"` // Legacy single token, superseded by sync_tokens
+	CachedAt           int64                  `protobuf:"varint,2,opt,name=cached_at,json=cachedAt,proto3" json:"cached_at,omitempty"`
+	Projects           []*PbProject           `protobuf:"bytes,3,rep,name=projects,proto3" json:"projects,omitempty"`
+	Sections           []*PbSection           `protobuf:"bytes,4,rep,name=sections,proto3" json:"sections,omitempty"`
+	Items              []*PbItem              `protobuf:"bytes,5,rep,name=items,proto3" json:"items,omitempty"`
+	Labels             []*PbLabel             `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty"`
+	Comments           []*PbComment           `protobuf:"bytes,7,rep,name=comments,proto3" json:"comments,omitempty"`
+	SyncTokens         map[string]string      `protobuf:"bytes,8,rep,name=sync_tokens,json=syncTokens,proto3" json:"sync_tokens,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Sync token per resource type
+	Account            string                 `protobuf:"bytes,9,opt,name=account,proto3" json:"account,omitempty"`                                                                                                   // Key of the account the cache was saved for
+	Schema             int32                  `protobuf:"varint,10,opt,name=schema,proto3" json:"schema,omitempty"`                                                                                                   // Schema of the cache, see CacheSchema; 0 if saved before schemas were numbered
+	User               *PbUser                `protobuf:"bytes,11,opt,name=user,proto3" json:"user,omitempty"`
+	Collaborators      []*PbCollaborator      `protobuf:"bytes,12,rep,name=collaborators,proto3" json:"collaborators,omitempty"`
+	CollaboratorStates []*PbCollaboratorState `protobuf:"bytes,13,rep,name=collaborator_states,json=collaboratorStates,proto3" json:"collaborator_states,omitempty"`
+	Reminders          []*PbReminder          `protobuf:"bytes,14,rep,name=reminders,proto3" json:"reminders,omitempty"`
+	WrittenThrough     []string               `protobuf:"bytes,15,rep,name=written_through,json=writtenThrough,proto3" json:"written_through,omitempty"` // IDs of the resources commands wrote through, not synced since
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
 func (x *CachedTodoistData) Reset() {
 	*x = CachedTodoistData{}
-	mi := &file_util_todoist_proto_msgTypes[7]
+	mi := &file_util_todoist_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -600,7 +1089,7 @@ func (x *CachedTodoistData) String() string {
 func (*CachedTodoistData) ProtoMessage() {}
 
 func (x *CachedTodoistData) ProtoReflect() protoreflect.Message {
-	mi := &file_util_todoist_proto_msgTypes[7]
+	mi := &file_util_todoist_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -613,7 +1102,7 @@ func (x *CachedTodoistData) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CachedTodoistData.ProtoReflect.Descriptor instead.
 func (*CachedTodoistData) Descriptor() ([]byte, []int) {
-	return file_util_todoist_proto_rawDescGZIP(), []int{7}
+	return file_util_todoist_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *CachedTodoistData) GetSyncToken() string {
@@ -665,6 +1154,123 @@ func (x *CachedTodoistData) GetComments() []*PbComment {
 	return nil
 }
 
+func (x *CachedTodoistData) GetSyncTokens() map[string]string {
+	if x != nil {
+		return x.SyncTokens
+	}
+	return nil
+}
+
+func (x *CachedTodoistData) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+func (x *CachedTodoistData) GetSchema() int32 {
+	if x != nil {
+		return x.Schema
+	}
+	return 0
+}
+
+func (x *CachedTodoistData) GetUser() *PbUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *CachedTodoistData) GetCollaborators() []*PbCollaborator {
+	if x != nil {
+		return x.Collaborators
+	}
+	return nil
+}
+
+func (x *CachedTodoistData) GetCollaboratorStates() []*PbCollaboratorState {
+	if x != nil {
+		return x.CollaboratorStates
+	}
+	return nil
+}
+
+func (x *CachedTodoistData) GetReminders() []*PbReminder {
+	if x != nil {
+		return x.Reminders
+	}
+	return nil
+}
+
+func (x *CachedTodoistData) GetWrittenThrough() []string {
+	if x != nil {
+		return x.WrittenThrough
+	}
+	return nil
+}
+
+// CompletedArchive is the local store of completed tasks, kept next to the cache
+type CompletedArchive struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Since         int64                  `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"` // Start of the period fetched, in Unix time
+	Until         int64                  `protobuf:"varint,2,opt,name=until,proto3" json:"until,omitempty"` // End of the period fetched, in Unix time
+	Items         []*PbItem              `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompletedArchive) Reset() {
+	*x = CompletedArchive{}
+	mi := &file_util_todoist_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompletedArchive) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompletedArchive) ProtoMessage() {}
+
+func (x *CompletedArchive) ProtoReflect() protoreflect.Message {
+	mi := &file_util_todoist_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompletedArchive.ProtoReflect.Descriptor instead.
+func (*CompletedArchive) Descriptor() ([]byte, []int) {
+	return file_util_todoist_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CompletedArchive) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *CompletedArchive) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *CompletedArchive) GetItems() []*PbItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
 var File_util_todoist_proto protoreflect.FileDescriptor
 
 const file_util_todoist_proto_rawDesc = "" +
@@ -680,21 +1286,38 @@ const file_util_todoist_proto_rawDesc = "" +
 	"\n" +
 	"due_string\x18\x03 \x01(\tR\tdueString\x12\x1a\n" +
 	"\bdatetime\x18\x04 \x01(\tR\bdatetime\x12\x1a\n" +
-	"\btimezone\x18\x05 \x01(\tR\btimezone\"\x81\x01\n" +
+	"\btimezone\x18\x05 \x01(\tR\btimezone\"4\n" +
+	"\n" +
+	"PbDeadline\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x12\n" +
+	"\x04lang\x18\x02 \x01(\tR\x04lang\"\xe4\x02\n" +
 	"\tPbProject\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
 	"\tparent_id\x18\x02 \x01(\tR\bparentId\x12\x12\n" +
 	"\x04name\x18\x03 \x01(\tR\x04name\x12\x14\n" +
 	"\x05color\x18\x04 \x01(\tR\x05color\x12\x1d\n" +
 	"\n" +
-	"view_style\x18\x05 \x01(\tR\tviewStyle\"\x82\x01\n" +
+	"view_style\x18\x05 \x01(\tR\tviewStyle\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\tR\tupdatedAt\x12\x1f\n" +
+	"\vis_favorite\x18\b \x01(\bR\n" +
+	"isFavorite\x12\x1f\n" +
+	"\vis_archived\x18\t \x01(\bR\n" +
+	"isArchived\x12\x1b\n" +
+	"\tis_shared\x18\n" +
+	" \x01(\bR\bisShared\x12#\n" +
+	"\rinbox_project\x18\v \x01(\bR\finboxProject\x12\x1f\n" +
+	"\vchild_order\x18\f \x01(\x05R\n" +
+	"childOrder\"\x82\x01\n" +
 	"\tPbSection\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
 	"\n" +
 	"project_id\x18\x02 \x01(\tR\tprojectId\x12\x12\n" +
 	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1c\n" +
 	"\tcollapsed\x18\x04 \x01(\bR\tcollapsed\x12\x14\n" +
-	"\x05order\x18\x05 \x01(\x05R\x05order\"\xf5\x02\n" +
+	"\x05order\x18\x05 \x01(\x05R\x05order\"\xea\x04\n" +
 	"\x06PbItem\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
 	"\n" +
@@ -711,17 +1334,56 @@ const file_util_todoist_proto_rawDesc = "" +
 	"\bduration\x18\n" +
 	" \x01(\v2\x10.util.PbDurationR\bduration\x12\x1d\n" +
 	"\x03due\x18\v \x01(\v2\v.util.PbDueR\x03due\x12!\n" +
-	"\fcompleted_at\x18\f \x01(\tR\vcompletedAt\"C\n" +
+	"\fcompleted_at\x18\f \x01(\tR\vcompletedAt\x12\x1b\n" +
+	"\tparent_id\x18\r \x01(\tR\bparentId\x12\x19\n" +
+	"\badded_at\x18\x0e \x01(\tR\aaddedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x0f \x01(\tR\tupdatedAt\x12,\n" +
+	"\bdeadline\x18\x10 \x01(\v2\x10.util.PbDeadlineR\bdeadline\x12'\n" +
+	"\x0fresponsible_uid\x18\x11 \x01(\tR\x0eresponsibleUid\x12&\n" +
+	"\x0fassigned_by_uid\x18\x12 \x01(\tR\rassignedByUid\x12\x1d\n" +
+	"\n" +
+	"note_count\x18\x13 \x01(\x05R\tnoteCount\"C\n" +
 	"\aPbLabel\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05color\x18\x03 \x01(\tR\x05color\"m\n" +
+	"\x05color\x18\x03 \x01(\tR\x05color\"\xa9\x01\n" +
 	"\tPbComment\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
 	"\atask_id\x18\x02 \x01(\tR\x06taskId\x12\x1d\n" +
 	"\n" +
 	"project_id\x18\x03 \x01(\tR\tprojectId\x12\x18\n" +
-	"\acontent\x18\x04 \x01(\tR\acontent\"\xa1\x02\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x12\x1b\n" +
+	"\tposted_at\x18\x05 \x01(\tR\bpostedAt\x12\x1d\n" +
+	"\n" +
+	"posted_uid\x18\x06 \x01(\tR\tpostedUid\"\xac\x01\n" +
+	"\n" +
+	"PbReminder\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\aitem_id\x18\x02 \x01(\tR\x06itemId\x12\x1d\n" +
+	"\n" +
+	"notify_uid\x18\x03 \x01(\tR\tnotifyUid\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x1d\n" +
+	"\x03due\x18\x05 \x01(\v2\v.util.PbDueR\x03due\x12#\n" +
+	"\rminute_offset\x18\x06 \x01(\x05R\fminuteOffset\"\xad\x01\n" +
+	"\x06PbUser\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1b\n" +
+	"\tfull_name\x18\x03 \x01(\tR\bfullName\x12\x1a\n" +
+	"\btimezone\x18\x04 \x01(\tR\btimezone\x12\x1d\n" +
+	"\n" +
+	"gmt_string\x18\x05 \x01(\tR\tgmtString\x12%\n" +
+	"\x0epremium_status\x18\x06 \x01(\tR\rpremiumStatus\"o\n" +
+	"\x0ePbCollaborator\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1b\n" +
+	"\tfull_name\x18\x03 \x01(\tR\bfullName\x12\x1a\n" +
+	"\btimezone\x18\x04 \x01(\tR\btimezone\"c\n" +
+	"\x13PbCollaboratorState\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\"\xdf\x05\n" +
 	"\x11CachedTodoistData\x12\x1d\n" +
 	"\n" +
 	"sync_token\x18\x01 \x01(\tR\tsyncToken\x12\x1b\n" +
@@ -730,7 +1392,24 @@ const file_util_todoist_proto_rawDesc = "" +
 	"\bsections\x18\x04 \x03(\v2\x0f.util.PbSectionR\bsections\x12\"\n" +
 	"\x05items\x18\x05 \x03(\v2\f.util.PbItemR\x05items\x12%\n" +
 	"\x06labels\x18\x06 \x03(\v2\r.util.PbLabelR\x06labels\x12+\n" +
-	"\bcomments\x18\a \x03(\v2\x0f.util.PbCommentR\bcommentsB%Z#github.com/layfellow/todoister/utilb\x06proto3"
+	"\bcomments\x18\a \x03(\v2\x0f.util.PbCommentR\bcomments\x12H\n" +
+	"\vsync_tokens\x18\b \x03(\v2'.util.CachedTodoistData.SyncTokensEntryR\n" +
+	"syncTokens\x12\x18\n" +
+	"\aaccount\x18\t \x01(\tR\aaccount\x12\x16\n" +
+	"\x06schema\x18\n" +
+	" \x01(\x05R\x06schema\x12 \n" +
+	"\x04user\x18\v \x01(\v2\f.util.PbUserR\x04user\x12:\n" +
+	"\rcollaborators\x18\f \x03(\v2\x14.util.PbCollaboratorR\rcollaborators\x12J\n" +
+	"\x13collaborator_states\x18\r \x03(\v2\x19.util.PbCollaboratorStateR\x12collaboratorStates\x12.\n" +
+	"\treminders\x18\x0e \x03(\v2\x10.util.PbReminderR\treminders\x12'\n" +
+	"\x0fwritten_through\x18\x0f \x03(\tR\x0ewrittenThrough\x1a=\n" +
+	"\x0fSyncTokensEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"b\n" +
+	"\x10CompletedArchive\x12\x14\n" +
+	"\x05since\x18\x01 \x01(\x03R\x05since\x12\x14\n" +
+	"\x05until\x18\x02 \x01(\x03R\x05until\x12\"\n" +
+	"\x05items\x18\x03 \x03(\v2\f.util.PbItemR\x05itemsB%Z#github.com/layfellow/todoister/utilb\x06proto3"
 
 var (
 	file_util_todoist_proto_rawDescOnce sync.Once
@@ -744,30 +1423,45 @@ func file_util_todoist_proto_rawDescGZIP() []byte {
 	return file_util_todoist_proto_rawDescData
 }
 
-var file_util_todoist_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_util_todoist_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_util_todoist_proto_goTypes = []any{
-	(*PbDuration)(nil),        // 0: util.PbDuration
-	(*PbDue)(nil),             // 1: util.PbDue
-	(*PbProject)(nil),         // 2: util.PbProject
-	(*PbSection)(nil),         // 3: util.PbSection
-	(*PbItem)(nil),            // 4: util.PbItem
-	(*PbLabel)(nil),           // 5: util.PbLabel
-	(*PbComment)(nil),         // 6: util.PbComment
-	(*CachedTodoistData)(nil), // 7: util.CachedTodoistData
+	(*PbDuration)(nil),          // 0: util.PbDuration
+	(*PbDue)(nil),               // 1: util.PbDue
+	(*PbDeadline)(nil),          // 2: util.PbDeadline
+	(*PbProject)(nil),           // 3: util.PbProject
+	(*PbSection)(nil),           // 4: util.PbSection
+	(*PbItem)(nil),              // 5: util.PbItem
+	(*PbLabel)(nil),             // 6: util.PbLabel
+	(*PbComment)(nil),           // 7: util.PbComment
+	(*PbReminder)(nil),          // 8: util.PbReminder
+	(*PbUser)(nil),              // 9: util.PbUser
+	(*PbCollaborator)(nil),      // 10: util.PbCollaborator
+	(*PbCollaboratorState)(nil), // 11: util.PbCollaboratorState
+	(*CachedTodoistData)(nil),   // 12: util.CachedTodoistData
+	(*CompletedArchive)(nil),    // 13: util.CompletedArchive
+	nil,                         // 14: util.CachedTodoistData.SyncTokensEntry
 }
 var file_util_todoist_proto_depIdxs = []int32{
-	0, // 0: util.PbItem.duration:type_name -> util.PbDuration
-	1, // 1: util.PbItem.due:type_name -> util.PbDue
-	2, // 2: util.CachedTodoistData.projects:type_name -> util.PbProject
-	3, // 3: util.CachedTodoistData.sections:type_name -> util.PbSection
-	4, // 4: util.CachedTodoistData.items:type_name -> util.PbItem
-	5, // 5: util.CachedTodoistData.labels:type_name -> util.PbLabel
-	6, // 6: util.CachedTodoistData.comments:type_name -> util.PbComment
-	7, // [7:7] is the sub-list for method output_type
-	7, // [7:7] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	0,  // 0: util.PbItem.duration:type_name -> util.PbDuration
+	1,  // 1: util.PbItem.due:type_name -> util.PbDue
+	2,  // 2: util.PbItem.deadline:type_name -> util.PbDeadline
+	1,  // 3: util.PbReminder.due:type_name -> util.PbDue
+	3,  // 4: util.CachedTodoistData.projects:type_name -> util.PbProject
+	4,  // 5: util.CachedTodoistData.sections:type_name -> util.PbSection
+	5,  // 6: util.CachedTodoistData.items:type_name -> util.PbItem
+	6,  // 7: util.CachedTodoistData.labels:type_name -> util.PbLabel
+	7,  // 8: util.CachedTodoistData.comments:type_name -> util.PbComment
+	14, // 9: util.CachedTodoistData.sync_tokens:type_name -> util.CachedTodoistData.SyncTokensEntry
+	9,  // 10: util.CachedTodoistData.user:type_name -> util.PbUser
+	10, // 11: util.CachedTodoistData.collaborators:type_name -> util.PbCollaborator
+	11, // 12: util.CachedTodoistData.collaborator_states:type_name -> util.PbCollaboratorState
+	8,  // 13: util.CachedTodoistData.reminders:type_name -> util.PbReminder
+	5,  // 14: util.CompletedArchive.items:type_name -> util.PbItem
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_util_todoist_proto_init() }
@@ -781,7 +1475,7 @@ func file_util_todoist_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_util_todoist_proto_rawDesc), len(file_util_todoist_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   0,
 		},